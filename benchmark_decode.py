@@ -0,0 +1,34 @@
+"""
+Times the Python side decoding the results written by the Go benchmarks, e.g.
+
+    mkdir -p /tmp/benchmark
+    GOSNMP_PYTHON_BENCHMARK_OUTPUT=/tmp/benchmark go test -run '^$' -bench MarshalMultiResults ./gosnmp_python_go/
+    python benchmark_decode.py /tmp/benchmark
+"""
+
+import os
+import sys
+import timeit
+
+# common.py stands alone, so there's no need to have built the Go side to load it
+sys.path.insert(0, os.path.join(os.path.dirname(os.path.abspath(__file__)), "gosnmp_python"))
+
+from common import handle_result  # noqa: E402
+
+
+def main(directory, repeat=5):
+    timings = {}
+    for name in ["results.json", "results.binary"]:
+        with open(os.path.join(directory, name), "r") as f:
+            result_string = f.read()
+
+        count = len(handle_result(result_string))
+        timings[name] = min(timeit.repeat(lambda: handle_result(result_string), number=1, repeat=repeat))
+
+        print("{0}: {1} varbinds from {2} bytes in {3:.3f}s".format(name, count, len(result_string), timings[name]))
+
+    print("binary is {0:.1f}x quicker to decode".format(timings["results.json"] / timings["results.binary"]))
+
+
+if __name__ == "__main__":
+    main(sys.argv[1])
@@ -1,4 +1,75 @@
-from gosnmp_python.common import GoRuntimeError, UnknownSNMPTypeError, SNMPVariable
-from gosnmp_python.rpc_session import create_snmpv1_session, create_snmpv2c_session, create_snmpv3_session, RPCSession
+from gosnmp_python.common import (
+    GoRuntimeError,
+    NonIncreasingOIDError,
+    UnknownSNMPTypeError,
+    SNMPVariable,
+    AnnotatedSNMPVariable,
+    PollResult,
+    ScheduledPollResult,
+    CounterRate,
+    WalkResult,
+    BulkGroup,
+    ProbeResult,
+    ProbeFailure,
+    MIBLoadResult,
+    IndexComponent,
+    UnknownValue,
+)
+from gosnmp_python.rpc_session import (
+    create_snmpv1_session,
+    create_snmpv2c_session,
+    create_snmpv3_session,
+    create_session,
+    create_session_from_uri,
+    poll_batch,
+    add_poll_schedule,
+    remove_poll_schedule,
+    poll_results,
+    poll_stats,
+    export_profiles,
+    import_profiles,
+    probe,
+    load_mibs,
+    resolve_oid,
+    lookup_oid,
+    decode_index,
+    encode_index,
+    RPCSession,
+)
 
-_ = (GoRuntimeError, UnknownSNMPTypeError, SNMPVariable, create_snmpv1_session, create_snmpv2c_session, create_snmpv3_session, RPCSession)
+_ = (
+    GoRuntimeError,
+    NonIncreasingOIDError,
+    UnknownSNMPTypeError,
+    SNMPVariable,
+    AnnotatedSNMPVariable,
+    PollResult,
+    ScheduledPollResult,
+    CounterRate,
+    WalkResult,
+    BulkGroup,
+    ProbeResult,
+    ProbeFailure,
+    MIBLoadResult,
+    IndexComponent,
+    UnknownValue,
+    create_snmpv1_session,
+    create_snmpv2c_session,
+    create_snmpv3_session,
+    create_session,
+    create_session_from_uri,
+    poll_batch,
+    add_poll_schedule,
+    remove_poll_schedule,
+    poll_results,
+    poll_stats,
+    export_profiles,
+    import_profiles,
+    probe,
+    load_mibs,
+    resolve_oid,
+    lookup_oid,
+    decode_index,
+    encode_index,
+    RPCSession,
+)
@@ -1,8 +1,14 @@
+import base64
 import json
+import struct
 from collections import namedtuple
 
 SNMPVariable = namedtuple("SNMPVariable", ["oid", "oid_index", "snmp_type", "value"])
 
+# an SNMPVariable from a session that annotates; name is like IF-MIB::ifInOctets, index is the rest of the OID (e.g. 3) and
+# display_value is the value as per its MIB (e.g. up, or a list of names for BITS; None if there's nothing better than the value)
+AnnotatedSNMPVariable = namedtuple("AnnotatedSNMPVariable", ["oid", "oid_index", "snmp_type", "value", "name", "index", "display_value"])
+
 MultiResult = namedtuple(
     "MultiResult",
     [
@@ -18,9 +24,41 @@ MultiResult = namedtuple(
         "FloatValue",
         "ByteArrayValue",
         "StringValue",
+        "Name",
+        "Index",
+        "DisplayValue",
     ],
 )
 
+# Name, Index and DisplayValue are only there if the session annotates
+MultiResult.__new__.__defaults__ = ("", "", None)
+
+CounterRate = namedtuple("CounterRate", ["oid", "value", "delta", "interval", "rate", "wrapped", "valid", "reason"])
+
+PollResult = namedtuple("PollResult", ["session_id", "results", "error", "latency", "rates"])
+
+ScheduledPollResult = namedtuple(
+    "ScheduledPollResult", ["schedule_id", "session_id", "results", "error", "latency", "rates", "scheduled_at", "started_at"]
+)
+
+WalkResult = namedtuple("WalkResult", ["results", "error", "last_oid"])
+
+BulkGroup = namedtuple("BulkGroup", ["oid", "non_repeater", "results"])
+
+ProbeFailure = namedtuple("ProbeFailure", ["candidate", "version", "error", "latency"])
+
+ProbeResult = namedtuple(
+    "ProbeResult", ["success", "candidate", "options", "version", "sys_object_id", "sys_descr", "latency", "failures"]
+)
+
+MIBLoadResult = namedtuple("MIBLoadResult", ["modules", "errors", "unresolved"])
+
+IndexComponent = namedtuple("IndexComponent", ["name", "type", "value"])
+
+# the value of an SNMPVariable with snmp_type unknown; tag is its BER tag and data its contents, as they came (tag 0 and no data if
+# they couldn't be had, e.g. on SNMPv3)
+UnknownValue = namedtuple("UnknownValue", ["tag", "data"])
+
 
 class UnknownSNMPTypeError(Exception):
     pass
@@ -30,6 +68,14 @@ class GoRuntimeError(Exception):
     pass
 
 
+class NonIncreasingOIDError(GoRuntimeError):
+    pass
+
+
+# how the Go side describes an agent returning OIDs out of order during a walk
+_NON_INCREASING_OID_ERRORS = ("OID not increasing: ", "OID loop detected: ")
+
+
 def handle_exception(method, args, other=None):
     try:
         return method(*args)
@@ -40,6 +86,9 @@ def handle_exception(method, args, other=None):
 
         e.args = new_args
 
+        if any(x in new_args[0] for x in _NON_INCREASING_OID_ERRORS):
+            raise NonIncreasingOIDError(e)
+
         raise GoRuntimeError(e)
 
 
@@ -58,13 +107,30 @@ def handle_multi_result_json(multi_result_json_string, session=None):
     return MultiResult(**multi_result_json)
 
 
-def _handle_multi_result(multi_result):
-    raw_oid = multi_result.OID.strip(". ")
+def _split_oid(raw_oid):
+    raw_oid = raw_oid.strip(". ")
 
     oid = ".{0}".format(".".join(raw_oid.split(".")[0:-1]).strip("."))
     oid_index = int(raw_oid.split(".")[-1])
 
-    if multi_result.Type in ["noSuchInstance", "noSuchObject", "endOfMibView"]:
+    return oid, oid_index
+
+
+def _annotate(snmp_variable, name, index, display_value):
+    if not name:
+        return snmp_variable
+
+    return AnnotatedSNMPVariable(*snmp_variable, name=name, index=index, display_value=display_value)
+
+
+def _handle_multi_result(multi_result):
+    return _annotate(_handle_unannotated_multi_result(multi_result), multi_result.Name, multi_result.Index, multi_result.DisplayValue)
+
+
+def _handle_unannotated_multi_result(multi_result):
+    oid, oid_index = _split_oid(multi_result.OID)
+
+    if multi_result.Type in ["noSuchInstance", "noSuchObject", "endOfMibView", "null"]:
         return SNMPVariable(
             oid=oid,
             oid_index=oid_index,
@@ -106,12 +172,220 @@ def _handle_multi_result(multi_result):
             snmp_type=multi_result.Type,
             value=multi_result.StringValue,
         )
+    elif multi_result.Type in ["unknown"]:
+        return SNMPVariable(
+            oid=oid,
+            oid_index=oid_index,
+            snmp_type=multi_result.Type,
+            value=UnknownValue(tag=multi_result.IntValue, data="".join([chr(x) for x in multi_result.ByteArrayValue])),
+        )
 
     raise UnknownSNMPTypeError("{0} represents an unknown SNMP type".format(multi_result))
 
 
+def handle_counter_rates(counter_rates_json):
+    if counter_rates_json is None:
+        return None
+
+    return [
+        CounterRate(
+            oid=x["OID"],
+            value=x["Value"],
+            delta=x["Delta"],
+            interval=x["Interval"],
+            rate=x["Rate"],
+            wrapped=x["Wrapped"],
+            valid=x["Valid"],
+            reason=x["Reason"] or None,
+        )
+        for x in counter_rates_json
+    ]
+
+
+def handle_mib_load_result(mib_load_result_json_string):
+    try:
+        mib_load_result_json = json.loads(mib_load_result_json_string)
+    except ValueError as e:
+        raise ValueError("load_mibs raised {0} while parsing {1}".format(e, repr(mib_load_result_json_string)))
+
+    return MIBLoadResult(
+        modules=mib_load_result_json["Modules"],
+        errors=mib_load_result_json["Errors"],
+        unresolved=mib_load_result_json["Unresolved"],
+    )
+
+
+def handle_index_components(index_components_json_string):
+    try:
+        index_components_json = json.loads(index_components_json_string)
+    except ValueError as e:
+        raise ValueError("decode_index raised {0} while parsing {1}".format(e, repr(index_components_json_string)))
+
+    return [
+        IndexComponent(
+            name=x["Name"] or None,
+            type=x["Type"],
+            value="".join([chr(y) for y in x["Value"]]) if x["Type"] == "string" else x["Value"],
+        )
+        for x in index_components_json
+    ]
+
+
 def handle_multi_result(multi_result_or_multi_results):
     if not isinstance(multi_result_or_multi_results, MultiResult):
         return [_handle_multi_result(x) for x in multi_result_or_multi_results]
 
     return _handle_multi_result(multi_result_or_multi_results)
+
+
+_BINARY_ENCODING_VERSION = 1
+_BINARY_FLAG_SINGLE = 1
+_BINARY_FLAG_ANNOTATED = 2
+
+_BINARY_DISPLAY_TEXT = 1
+_BINARY_DISPLAY_LIST = 2
+
+_BINARY_TYPES = {
+    0: "noSuchInstance",
+    1: "noSuchObject",
+    2: "endOfMibView",
+    3: "bool",
+    4: "int",
+    5: "float",
+    6: "bytearray",
+    7: "string",
+    8: "null",
+    9: "unknown",
+}
+
+_HEADER = struct.Struct(">BBI")
+_VARBIND_HEADER = struct.Struct(">BH")
+_INT = struct.Struct(">q")
+_FLOAT = struct.Struct(">d")
+_LENGTH = struct.Struct(">I")
+_SHORT_LENGTH = struct.Struct(">H")
+
+
+def handle_binary_result(binary_result_string, session=None):
+    try:
+        data = base64.b64decode(binary_result_string)
+        version, flags, count = _HEADER.unpack_from(data, 0)
+    except (ValueError, TypeError, struct.error) as e:
+        raise ValueError("{0} raised {1} while parsing {2}".format(session, e, repr(binary_result_string)))
+
+    if version != _BINARY_ENCODING_VERSION:
+        raise ValueError("{0} received unsupported binary encoding version {1}".format(session, version))
+
+    offset = _HEADER.size
+
+    snmp_variables = []
+    for _ in range(count):
+        type_code, oid_length = _VARBIND_HEADER.unpack_from(data, offset)
+        offset += _VARBIND_HEADER.size
+
+        oid, oid_index = _split_oid(data[offset : offset + oid_length].decode("ascii"))
+        offset += oid_length
+
+        snmp_type = _BINARY_TYPES.get(type_code)
+        if snmp_type is None:
+            raise UnknownSNMPTypeError("type code {0} for {1}.{2} represents an unknown SNMP type".format(type_code, oid, oid_index))
+
+        value = None
+        if snmp_type == "bool":
+            value = data[offset] != 0
+            offset += 1
+        elif snmp_type == "int":
+            value = _INT.unpack_from(data, offset)[0]
+            offset += _INT.size
+        elif snmp_type == "float":
+            value = _FLOAT.unpack_from(data, offset)[0]
+            offset += _FLOAT.size
+        elif snmp_type in ["bytearray", "string"]:
+            length = _LENGTH.unpack_from(data, offset)[0]
+            offset += _LENGTH.size
+            raw_value = data[offset : offset + length]
+            offset += length
+            value = raw_value.decode("latin-1") if snmp_type == "bytearray" else raw_value.decode("utf-8", "replace")
+        elif snmp_type == "unknown":
+            tag = data[offset]
+            offset += 1
+            length = _LENGTH.unpack_from(data, offset)[0]
+            offset += _LENGTH.size
+            value = UnknownValue(tag=tag, data=data[offset : offset + length].decode("latin-1"))
+            offset += length
+
+        name, index, display_value = "", "", None
+        if flags & _BINARY_FLAG_ANNOTATED:
+            length = _SHORT_LENGTH.unpack_from(data, offset)[0]
+            offset += _SHORT_LENGTH.size
+            name = data[offset : offset + length].decode("ascii")
+            offset += length
+
+            length = _SHORT_LENGTH.unpack_from(data, offset)[0]
+            offset += _SHORT_LENGTH.size
+            index = data[offset : offset + length].decode("ascii")
+            offset += length
+
+            display_kind = data[offset]
+            offset += 1
+
+            if display_kind == _BINARY_DISPLAY_TEXT:
+                length = _LENGTH.unpack_from(data, offset)[0]
+                offset += _LENGTH.size
+                display_value = data[offset : offset + length].decode("utf-8", "replace")
+                offset += length
+            elif display_kind == _BINARY_DISPLAY_LIST:
+                count = _SHORT_LENGTH.unpack_from(data, offset)[0]
+                offset += _SHORT_LENGTH.size
+                display_value = []
+                for _ in range(count):
+                    length = _SHORT_LENGTH.unpack_from(data, offset)[0]
+                    offset += _SHORT_LENGTH.size
+                    display_value.append(data[offset : offset + length].decode("utf-8", "replace"))
+                    offset += length
+
+        snmp_variables.append(
+            _annotate(SNMPVariable(oid=oid, oid_index=oid_index, snmp_type=snmp_type, value=value), name, index, display_value)
+        )
+
+    if flags & _BINARY_FLAG_SINGLE:
+        return snmp_variables[0]
+
+    return snmp_variables
+
+
+def handle_result(result_string, session=None):
+    # JSON is always an object or a list; base64 never starts with either
+    if result_string[:1] in ("[", "{"):
+        return handle_multi_result(handle_multi_result_json(result_string, session))
+
+    return handle_binary_result(result_string, session)
+
+
+def handle_partial_walk_result(partial_walk_result_json_string, session=None):
+    try:
+        partial_walk_result_json = json.loads(partial_walk_result_json_string)
+    except ValueError as e:
+        raise ValueError("{0} raised {1} while parsing {2}".format(session, e, repr(partial_walk_result_json_string)))
+
+    return WalkResult(
+        results=handle_multi_result([MultiResult(**x) for x in partial_walk_result_json["Results"]]),
+        error=partial_walk_result_json["Error"] or None,
+        last_oid=partial_walk_result_json["LastOID"] or None,
+    )
+
+
+def handle_bulk_groups(bulk_groups_json_string, session=None):
+    try:
+        bulk_groups_json = json.loads(bulk_groups_json_string)
+    except ValueError as e:
+        raise ValueError("{0} raised {1} while parsing {2}".format(session, e, repr(bulk_groups_json_string)))
+
+    return [
+        BulkGroup(
+            oid=x["OID"],
+            non_repeater=x["NonRepeater"],
+            results=handle_multi_result([MultiResult(**y) for y in x["Results"]]),
+        )
+        for x in bulk_groups_json
+    ]
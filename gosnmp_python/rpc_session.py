@@ -2,22 +2,58 @@ import json
 import re
 from threading import RLock
 
+from gosnmp_python.built.go import Slice_string
 from gosnmp_python.built.gosnmp_python_go import (
     NewRPCSessionV1,
     NewRPCSessionV2c,
     NewRPCSessionV3,
+    NewRPCSessionFromOptions,
+    NewRPCSessionFromURI,
     RPCConnect,
     RPCGet,
     RPCGetNext,
-    RPCGetBulk,
+    RPCGetBulkOIDs,
+    RPCGetBulkGrouped,
     RPCWalk,
     RPCWalkBulk,
+    RPCWalkWithOptions,
+    RPCWalkBulkWithOptions,
+    RPCWalkMany,
+    RPCBatch,
+    RPCPollBatch,
+    RPCAddPollSchedule,
+    RPCRemovePollSchedule,
+    RPCPollResults,
+    RPCPollStats,
+    RPCExportProfiles,
+    RPCImportProfiles,
+    RPCProbe,
+    RPCLoadMIBs,
+    RPCResolveOID,
+    RPCLookupOID,
+    RPCDecodeIndex,
+    RPCEncodeIndex,
     RPCSetInteger,
     RPCSetIPAddress,
     RPCSetString,
+    RPCSetMany,
     RPCClose,
 )
-from gosnmp_python.common import handle_exception, handle_multi_result, handle_multi_result_json
+from gosnmp_python.common import (
+    MultiResult,
+    PollResult,
+    ProbeFailure,
+    ProbeResult,
+    ScheduledPollResult,
+    handle_bulk_groups,
+    handle_counter_rates,
+    handle_exception,
+    handle_index_components,
+    handle_mib_load_result,
+    handle_multi_result,
+    handle_partial_walk_result,
+    handle_result,
+)
 
 _new_session_lock = RLock()
 
@@ -37,12 +73,58 @@ def _new_rpc_session_v3(*args):
         return handle_exception(NewRPCSessionV3, args)
 
 
+def _new_rpc_session_from_options(*args):
+    with _new_session_lock:
+        return handle_exception(NewRPCSessionFromOptions, args)
+
+
+def _new_rpc_session_from_uri(*args):
+    with _new_session_lock:
+        return handle_exception(NewRPCSessionFromURI, args)
+
+
 _IP_ADDRESS = re.compile(r"^[0-2]?[0-9]?[0-9]{1}\.[0-2]?[0-9]?[0-9]{1}\.[0-2]?[0-9]?[0-9]{1}\.[0-2]?[0-9]?[0-9]{1}$")
 
 _V1 = "v1"
 _V2C = "v2c"
 _V3 = "v3"
 
+_VERSIONS = {
+    "1": _V1,
+    "v1": _V1,
+    "2": _V2C,
+    "2c": _V2C,
+    "v2c": _V2C,
+    "3": _V3,
+    "v3": _V3,
+}
+
+
+def _walk_options(stop_oid, max_varbinds, exclude, resume_oid=None, partial=False, allow_non_increasing=False):
+    if stop_oid is None and max_varbinds is None and exclude is None and resume_oid is None and not partial and not allow_non_increasing:
+        return None
+
+    if exclude is not None and not isinstance(exclude, (list, tuple)):
+        exclude = [exclude]
+
+    return json.dumps(
+        {
+            "stop_oid": str(stop_oid) if stop_oid is not None else "",
+            "max_varbinds": int(max_varbinds) if max_varbinds is not None else 0,
+            "exclude": [str(oid) for oid in exclude] if exclude is not None else [],
+            "resume_oid": str(resume_oid) if resume_oid is not None else "",
+            "partial": bool(partial),
+            "allow_non_increasing": bool(allow_non_increasing),
+        }
+    )
+
+
+def _call_overrides(timeout, retries):
+    return (
+        float(timeout) if timeout is not None else 0.0,
+        int(retries) if retries is not None else -1,
+    )
+
 
 class RPCSession(object):
     def __init__(self, session_id, version, **kwargs):
@@ -64,67 +146,147 @@ class RPCSession(object):
     def connect(self):
         return handle_exception(RPCConnect, (self._session_id,), self)
 
-    def get(self, oid):
+    def get(self, oid, timeout=None, retries=None):
         oid = str(oid)
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(RPCGet, (self._session_id, oid), self),
-                self,
-            )
+        return handle_result(
+            handle_exception(RPCGet, (self._session_id, oid) + _call_overrides(timeout, retries), self),
+            self,
         )
 
-    def get_next(self, oid):
+    def get_next(self, oid, timeout=None, retries=None):
         oid = str(oid)
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(RPCGetNext, (self._session_id, oid), self),
-                self,
-            ),
+        return handle_result(
+            handle_exception(RPCGetNext, (self._session_id, oid) + _call_overrides(timeout, retries), self),
+            self,
         )
 
-    def get_bulk(self, oids, non_repeaters, max_repetitions):
-        if self._version == _V1:
+    def get_bulk(self, oids, non_repeaters, max_repetitions, timeout=None, retries=None, grouped=False):
+        if self._version == _V1 and not self._kwargs.get("emulate_getbulk"):
             raise NotImplementedError("cannot call GETBULK with SNMPv1")
 
         if not isinstance(oids, (list, tuple)):
             oids = [oids]
 
-        # TODO: fix this hack- gopy not happy receiving lists
-        oids = json.dumps(oids)
+        oids = Slice_string([str(oid) for oid in oids])
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(RPCGetBulk, (self._session_id, oids, non_repeaters, max_repetitions), self),
+        # a BulkGroup for each of the oids (non-repeaters first), each column trimmed after any endOfMibView
+        if grouped:
+            return handle_bulk_groups(
+                handle_exception(
+                    RPCGetBulkGrouped,
+                    (self._session_id, oids, non_repeaters, max_repetitions) + _call_overrides(timeout, retries),
+                    self,
+                ),
+                self,
+            )
+
+        return handle_result(
+            handle_exception(
+                RPCGetBulkOIDs,
+                (self._session_id, oids, non_repeaters, max_repetitions) + _call_overrides(timeout, retries),
                 self,
             ),
+            self,
         )
 
-    def walk(self, oid):
+    def walk(
+        self,
+        oid,
+        timeout=None,
+        retries=None,
+        stop_oid=None,
+        max_varbinds=None,
+        exclude=None,
+        resume_oid=None,
+        partial=False,
+        allow_non_increasing=False,
+    ):
         oid = str(oid)
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(RPCWalk, (self._session_id, oid), self),
+        options = _walk_options(stop_oid, max_varbinds, exclude, resume_oid, partial, allow_non_increasing)
+        if partial:
+            return handle_partial_walk_result(
+                handle_exception(RPCWalkWithOptions, (self._session_id, oid, options) + _call_overrides(timeout, retries), self),
                 self,
-            ),
+            )
+
+        if options is not None:
+            return handle_result(
+                handle_exception(RPCWalkWithOptions, (self._session_id, oid, options) + _call_overrides(timeout, retries), self),
+                self,
+            )
+
+        return handle_result(
+            handle_exception(RPCWalk, (self._session_id, oid) + _call_overrides(timeout, retries), self),
+            self,
         )
 
-    def walk_bulk(self, oid):
-        if self._version == _V1:
+    def walk_bulk(
+        self,
+        oid,
+        timeout=None,
+        retries=None,
+        stop_oid=None,
+        max_varbinds=None,
+        exclude=None,
+        resume_oid=None,
+        partial=False,
+        allow_non_increasing=False,
+    ):
+        if self._version == _V1 and not self._kwargs.get("emulate_getbulk"):
             raise NotImplementedError("cannot call BULKWALK with SNMPv1")
 
         oid = str(oid)
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(RPCWalkBulk, (self._session_id, oid), self),
+        options = _walk_options(stop_oid, max_varbinds, exclude, resume_oid, partial, allow_non_increasing)
+        if partial:
+            return handle_partial_walk_result(
+                handle_exception(RPCWalkBulkWithOptions, (self._session_id, oid, options) + _call_overrides(timeout, retries), self),
                 self,
-            ),
+            )
+
+        if options is not None:
+            return handle_result(
+                handle_exception(RPCWalkBulkWithOptions, (self._session_id, oid, options) + _call_overrides(timeout, retries), self),
+                self,
+            )
+
+        return handle_result(
+            handle_exception(RPCWalkBulk, (self._session_id, oid) + _call_overrides(timeout, retries), self),
+            self,
         )
 
-    def set(self, oid, value, is_ip_address=None):
+    def batch(self, operations, timeout=None, retries=None):
+        operations = json.dumps(operations)
+
+        batch_results_json_string = handle_exception(RPCBatch, (self._session_id, operations) + _call_overrides(timeout, retries), self)
+
+        try:
+            batch_results_json = json.loads(batch_results_json_string)
+        except ValueError as e:
+            raise ValueError("{0} raised {1} while parsing {2}".format(self, e, repr(batch_results_json_string)))
+
+        return [
+            (handle_multi_result([MultiResult(**x) for x in batch_result["Results"]]), batch_result["Error"] or None)
+            for batch_result in batch_results_json
+        ]
+
+    def walk_many(self, oids, timeout=None, retries=None, stop_oid=None, max_varbinds=None, exclude=None, allow_non_increasing=False):
+        if not isinstance(oids, (list, tuple)):
+            oids = [oids]
+
+        oids = Slice_string([str(oid) for oid in oids])
+
+        options = _walk_options(stop_oid, max_varbinds, exclude, allow_non_increasing=allow_non_increasing) or ""
+
+        return handle_result(
+            handle_exception(RPCWalkMany, (self._session_id, oids, options) + _call_overrides(timeout, retries), self),
+            self,
+        )
+
+    def set(self, oid, value, is_ip_address=None, timeout=None, retries=None):
         if not isinstance(value, (int, str)):
             raise TypeError("gosnmp_python only supports SNMP set for integers and strings")
 
@@ -136,24 +298,44 @@ class RPCSession(object):
         else:
             method = RPCSetInteger
 
-        return handle_multi_result(
-            handle_multi_result_json(
-                handle_exception(method, (self._session_id, oid, value), self),
+        return handle_result(
+            handle_exception(method, (self._session_id, oid, value) + _call_overrides(timeout, retries), self),
+            self,
+        )
+
+    def set_many(self, varbinds, timeout=None, retries=None):
+        oids, value_types, values = [], [], []
+        for oid, value_type, value in varbinds:
+            oids.append(str(oid))
+            value_types.append(str(value_type))
+            values.append(str(value))
+
+        return handle_result(
+            handle_exception(
+                RPCSetMany,
+                (self._session_id, Slice_string(oids), Slice_string(value_types), Slice_string(values)) + _call_overrides(timeout, retries),
                 self,
             ),
+            self,
         )
 
     def close(self):
         return handle_exception(RPCClose, (self._session_id,), self)
 
 
-def create_snmpv1_session(hostname, community, port=161, timeout=5, retries=1):
+def create_snmpv1_session(hostname, community, port=161, timeout=5, retries=1, local_address=None, local_port=0, local_interface=None):
+    local_address = local_address if local_address is not None else ""
+    local_interface = local_interface if local_interface is not None else ""
+
     session_id = _new_rpc_session_v1(
         str(hostname),
         int(port),
         str(community),
-        int(timeout),
+        float(timeout),
         int(retries),
+        str(local_address),
+        int(local_port),
+        str(local_interface),
     )
 
     kwargs = {
@@ -162,18 +344,27 @@ def create_snmpv1_session(hostname, community, port=161, timeout=5, retries=1):
         "port": port,
         "timeout": timeout,
         "retries": retries,
+        "local_address": local_address,
+        "local_port": local_port,
+        "local_interface": local_interface,
     }
 
     return RPCSession(session_id=session_id, version=_V1, **kwargs)
 
 
-def create_snmpv2c_session(hostname, community, port=161, timeout=5, retries=1):
+def create_snmpv2c_session(hostname, community, port=161, timeout=5, retries=1, local_address=None, local_port=0, local_interface=None):
+    local_address = local_address if local_address is not None else ""
+    local_interface = local_interface if local_interface is not None else ""
+
     session_id = _new_rpc_session_v2c(
         str(hostname),
         int(port),
         str(community),
-        int(timeout),
+        float(timeout),
         int(retries),
+        str(local_address),
+        int(local_port),
+        str(local_interface),
     )
 
     kwargs = {
@@ -182,6 +373,9 @@ def create_snmpv2c_session(hostname, community, port=161, timeout=5, retries=1):
         "port": port,
         "timeout": timeout,
         "retries": retries,
+        "local_address": local_address,
+        "local_port": local_port,
+        "local_interface": local_interface,
     }
 
     return RPCSession(session_id=session_id, version=_V2C, **kwargs)
@@ -199,8 +393,13 @@ def create_snmpv3_session(
     port=161,
     timeout=5,
     retries=1,
+    local_address=None,
+    local_port=0,
+    local_interface=None,
 ):
     context_name = context_name if context_name is not None else ""
+    local_address = local_address if local_address is not None else ""
+    local_interface = local_interface if local_interface is not None else ""
 
     session_id = _new_rpc_session_v3(
         str(hostname),
@@ -212,8 +411,11 @@ def create_snmpv3_session(
         str(security_level),
         str(auth_protocol),
         str(privacy_protocol),
-        int(timeout),
+        float(timeout),
         int(retries),
+        str(local_address),
+        int(local_port),
+        str(local_interface),
     )
 
     kwargs = {
@@ -228,6 +430,199 @@ def create_snmpv3_session(
         "port": port,
         "timeout": timeout,
         "retries": retries,
+        "local_address": local_address,
+        "local_port": local_port,
+        "local_interface": local_interface,
     }
 
     return RPCSession(session_id=session_id, version=_V3, **kwargs)
+
+
+def create_session(**options):
+    session_id = _new_rpc_session_from_options(json.dumps(options))
+
+    version = _VERSIONS.get(str(options.get("version", "2c")).lower())
+
+    return RPCSession(session_id=session_id, version=version, **options)
+
+
+def create_session_from_uri(uri):
+    session_id = _new_rpc_session_from_uri(str(uri))
+
+    return RPCSession(session_id=session_id, version=None, uri=uri)
+
+
+def poll_batch(jobs, workers=None, per_host=None):
+    real_jobs = []
+    for job in jobs:
+        job = dict(job)
+
+        session = job.pop("session", None)
+        if session is not None:
+            job["session_id"] = session._session_id
+
+        real_jobs.append(job)
+
+    batch = {"jobs": real_jobs}
+
+    if workers is not None:
+        batch["workers"] = int(workers)
+
+    if per_host is not None:
+        batch["per_host"] = int(per_host)
+
+    poll_results_json_string = handle_exception(RPCPollBatch, (json.dumps(batch),))
+
+    try:
+        poll_results_json = json.loads(poll_results_json_string)
+    except ValueError as e:
+        raise ValueError("poll_batch raised {0} while parsing {1}".format(e, repr(poll_results_json_string)))
+
+    return [
+        PollResult(
+            session_id=poll_result["SessionID"],
+            results=handle_multi_result([MultiResult(**x) for x in poll_result["Results"]]),
+            error=poll_result["Error"] or None,
+            latency=poll_result["Latency"],
+            rates=handle_counter_rates(poll_result.get("Rates")),
+        )
+        for poll_result in poll_results_json
+    ]
+
+
+def add_poll_schedule(
+    session, operation, oids, interval, jitter=0, non_repeaters=0, max_repetitions=0, timeout=None, retries=None, rates=False
+):
+    if not isinstance(oids, (list, tuple)):
+        oids = [oids]
+
+    schedule = {
+        "session_id": session._session_id,
+        "operation": str(operation),
+        "oids": [str(oid) for oid in oids],
+        "non_repeaters": int(non_repeaters),
+        "max_repetitions": int(max_repetitions),
+        "interval": float(interval),
+        "jitter": float(jitter),
+        "rates": bool(rates),
+    }
+
+    if timeout is not None:
+        schedule["timeout"] = float(timeout)
+
+    if retries is not None:
+        schedule["retries"] = int(retries)
+
+    return handle_exception(RPCAddPollSchedule, (json.dumps(schedule),))
+
+
+def remove_poll_schedule(schedule_id):
+    return handle_exception(RPCRemovePollSchedule, (int(schedule_id),))
+
+
+def poll_results(max_results=0):
+    poll_results_json_string = handle_exception(RPCPollResults, (int(max_results),))
+
+    try:
+        poll_results_json = json.loads(poll_results_json_string)
+    except ValueError as e:
+        raise ValueError("poll_results raised {0} while parsing {1}".format(e, repr(poll_results_json_string)))
+
+    return [
+        ScheduledPollResult(
+            schedule_id=poll_result["ScheduleID"],
+            session_id=poll_result["SessionID"],
+            results=handle_multi_result([MultiResult(**x) for x in poll_result["Results"]]),
+            error=poll_result["Error"] or None,
+            latency=poll_result["Latency"],
+            rates=handle_counter_rates(poll_result.get("Rates")),
+            scheduled_at=poll_result["ScheduledAt"],
+            started_at=poll_result["StartedAt"],
+        )
+        for poll_result in poll_results_json
+    ]
+
+
+def poll_stats():
+    return json.loads(handle_exception(RPCPollStats, ()))
+
+
+def export_profiles():
+    return handle_exception(RPCExportProfiles, ())
+
+
+def import_profiles(profiles):
+    if isinstance(profiles, (list, tuple)):
+        profiles = json.dumps(profiles)
+
+    return handle_exception(RPCImportProfiles, (profiles,))
+
+
+def probe(target, candidates, order=None, port=None, timeout=None, retries=None):
+    request = {"candidates": list(candidates)}
+
+    if order is not None:
+        request["order"] = [str(x) for x in order]
+
+    if port is not None:
+        request["port"] = int(port)
+
+    if timeout is not None:
+        request["timeout"] = float(timeout)
+
+    if retries is not None:
+        request["retries"] = int(retries)
+
+    probe_result_json_string = handle_exception(RPCProbe, (str(target), json.dumps(request)))
+
+    try:
+        probe_result_json = json.loads(probe_result_json_string)
+    except ValueError as e:
+        raise ValueError("probe raised {0} while parsing {1}".format(e, repr(probe_result_json_string)))
+
+    return ProbeResult(
+        success=probe_result_json["Success"],
+        candidate=probe_result_json["Candidate"] if probe_result_json["Success"] else None,
+        options=probe_result_json["Options"],
+        version=probe_result_json["Version"] or None,
+        sys_object_id=probe_result_json["SysObjectID"] or None,
+        sys_descr=probe_result_json["SysDescr"] or None,
+        latency=probe_result_json["Latency"],
+        failures=[
+            ProbeFailure(
+                candidate=x["Candidate"],
+                version=x["Version"] or None,
+                error=x["Error"],
+                latency=x["Latency"],
+            )
+            for x in probe_result_json["Failures"]
+        ],
+    )
+
+
+def load_mibs(directory):
+    return handle_mib_load_result(handle_exception(RPCLoadMIBs, (str(directory),)))
+
+
+def resolve_oid(name):
+    return handle_exception(RPCResolveOID, (str(name),))
+
+
+def lookup_oid(oid):
+    return handle_exception(RPCLookupOID, (str(oid),))
+
+
+def _index_table(table):
+    # a table (or row, or column) by name, or an inline spec
+    if isinstance(table, (list, tuple)):
+        return json.dumps(list(table))
+
+    return str(table)
+
+
+def decode_index(table, index):
+    return handle_index_components(handle_exception(RPCDecodeIndex, (_index_table(table), str(index))))
+
+
+def encode_index(table, values):
+    return handle_exception(RPCEncodeIndex, (_index_table(table), json.dumps(list(values))))
@@ -0,0 +1,396 @@
+package gosnmp_python_go
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testVarbind is an object served by a testAgent, with its value as it goes on the wire (so any type can be served)
+type testVarbind struct {
+	oid  string
+	tag  byte
+	data []byte
+}
+
+// testAgent is an SNMPv1 / SNMPv2c agent on loopback that answers Get, GetNext and GetBulk from a fixed set of objects
+type testAgent struct {
+	conn      net.PacketConn
+	varbinds  []testVarbind // in OID order
+	mutex     sync.Mutex
+	requests  int
+	jumps     map[string]string // what comes after an OID, in place of what really does (see setJump)
+	community string            // if set, requests with any other community are ignored (as a real agent does)
+}
+
+func berLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+
+	encoded := make([]byte, 0)
+	for ; length > 0; length >>= 8 {
+		encoded = append([]byte{byte(length)}, encoded...)
+	}
+
+	return append([]byte{0x80 | byte(len(encoded))}, encoded...)
+}
+
+func berTLV(tag byte, data []byte) []byte {
+	return append(append([]byte{tag}, berLength(len(data))...), data...)
+}
+
+func berInteger(value int64) []byte {
+	encoded := []byte{byte(value)}
+	for value >= 0x80 || value < -0x80 {
+		value >>= 8
+		encoded = append([]byte{byte(value)}, encoded...)
+	}
+
+	return encoded
+}
+
+func berUnsigned(value uint64) []byte {
+	encoded := []byte{byte(value)}
+	for value >>= 8; value > 0; value >>= 8 {
+		encoded = append([]byte{byte(value)}, encoded...)
+	}
+
+	if encoded[0]&0x80 != 0 {
+		encoded = append([]byte{0}, encoded...)
+	}
+
+	return encoded
+}
+
+func berOID(oid string) []byte {
+	parts := parseOIDParts(oid)
+
+	encoded := []byte{byte(parts[0]*40 + parts[1])}
+	for _, part := range parts[2:] {
+		chunk := []byte{byte(part & 0x7f)}
+		for part >>= 7; part > 0; part >>= 7 {
+			chunk = append([]byte{0x80 | byte(part&0x7f)}, chunk...)
+		}
+
+		encoded = append(encoded, chunk...)
+	}
+
+	return encoded
+}
+
+func parseOIDParts(oid string) []uint64 {
+	parts := make([]uint64, 0)
+	for _, part := range strings.Split(strings.Trim(oid, "."), ".") {
+		value, _ := strconv.ParseUint(part, 10, 64)
+		parts = append(parts, value)
+	}
+
+	return parts
+}
+
+func decodeBEROID(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	parts := []string{strconv.Itoa(int(data[0]) / 40), strconv.Itoa(int(data[0]) % 40)}
+
+	part := uint64(0)
+	for _, b := range data[1:] {
+		part = part<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			parts = append(parts, strconv.FormatUint(part, 10))
+			part = 0
+		}
+	}
+
+	return "." + strings.Join(parts, ".")
+}
+
+func newTestAgent(t testing.TB, varbinds []testVarbind) *testAgent {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	a := &testAgent{
+		conn:     conn,
+		varbinds: append([]testVarbind{}, varbinds...),
+		jumps:    make(map[string]string),
+	}
+
+	sort.Slice(a.varbinds, func(i, j int) bool {
+		return compareOIDs(a.varbinds[i].oid, a.varbinds[j].oid) < 0
+	})
+
+	go a.serve()
+
+	return a
+}
+
+func (a *testAgent) port() int {
+	return a.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (a *testAgent) close() {
+	_ = a.conn.Close()
+}
+
+func (a *testAgent) getRequests() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.requests
+}
+
+// setJump has the agent misbehave by answering a GetNext (or GetBulk) for from with to (which may be from itself, or come before it)
+func (a *testAgent) setJump(from string, to string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.jumps[from] = to
+}
+
+// setCommunity makes the agent ignore requests that don't have the given community, so they time out
+func (a *testAgent) setCommunity(community string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.community = community
+}
+
+func (a *testAgent) get(oid string) testVarbind {
+	for _, varbind := range a.varbinds {
+		if compareOIDs(varbind.oid, oid) == 0 {
+			return varbind
+		}
+	}
+
+	return testVarbind{oid: oid, tag: 0x81} // noSuchInstance
+}
+
+// set replaces the object (or adds it), with its value as it came; the objects are only ever touched by serve, so there's no lock
+func (a *testAgent) set(varbind testVarbind) {
+	for i := range a.varbinds {
+		if a.varbinds[i].oid == varbind.oid {
+			a.varbinds[i] = varbind
+			return
+		}
+	}
+
+	a.varbinds = append(a.varbinds, varbind)
+	sort.Slice(a.varbinds, func(i, j int) bool {
+		return compareOIDs(a.varbinds[i].oid, a.varbinds[j].oid) < 0
+	})
+}
+
+func (a *testAgent) getNext(oid string) testVarbind {
+	a.mutex.Lock()
+	to, ok := a.jumps[oid]
+	a.mutex.Unlock()
+
+	if ok {
+		return a.get(to)
+	}
+
+	for _, varbind := range a.varbinds {
+		if compareOIDs(varbind.oid, oid) > 0 {
+			return varbind
+		}
+	}
+
+	return testVarbind{oid: oid, tag: 0x82} // endOfMibView
+}
+
+func (a *testAgent) serve() {
+	buf := make([]byte, 65535)
+
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		a.mutex.Lock()
+		a.requests++
+		a.mutex.Unlock()
+
+		response, ok := a.respond(buf[:n])
+		if ok {
+			_, _ = a.conn.WriteTo(response, addr)
+		}
+	}
+}
+
+// respond answers a request (SEQUENCE { version, community, PDU { request-id, non-repeaters / error-status, max-repetitions /
+// error-index, SEQUENCE { SEQUENCE { name, value }, ... } } })
+func (a *testAgent) respond(request []byte) ([]byte, bool) {
+	_, start, end, ok := readBERHeader(request)
+	if !ok {
+		return nil, false
+	}
+	request = request[start:end]
+
+	// version, community, then the PDU
+	fields := make([][]byte, 0)
+	for i := 0; i < 2; i++ {
+		_, _, end, ok = readBERHeader(request)
+		if !ok {
+			return nil, false
+		}
+		fields = append(fields, request[:end])
+		request = request[end:]
+	}
+
+	a.mutex.Lock()
+	community := a.community
+	a.mutex.Unlock()
+
+	if community != "" {
+		_, start, end, _ = readBERHeader(fields[1])
+		if string(fields[1][start:end]) != community {
+			return nil, false
+		}
+	}
+
+	pduType, start, end, ok := readBERHeader(request)
+	if !ok {
+		return nil, false
+	}
+	request = request[start:end]
+
+	// request-id, non-repeaters, max-repetitions (as they are for a GetBulk)
+	for i := 0; i < 3; i++ {
+		_, _, end, ok = readBERHeader(request)
+		if !ok {
+			return nil, false
+		}
+		fields = append(fields, request[:end])
+		request = request[end:]
+	}
+
+	nonRepeaters, maxRepetitions := int(fields[3][len(fields[3])-1]), int(fields[4][len(fields[4])-1])
+
+	_, start, end, ok = readBERHeader(request)
+	if !ok {
+		return nil, false
+	}
+	request = request[start:end]
+
+	oids := make([]string, 0)
+	values := make([]testVarbind, 0) // for a SetRequest
+	for len(request) > 0 {
+		_, start, end, ok = readBERHeader(request)
+		if !ok {
+			return nil, false
+		}
+		varbind := request[start:end]
+		request = request[end:]
+
+		_, start, end, ok = readBERHeader(varbind)
+		if !ok {
+			return nil, false
+		}
+		oid := decodeBEROID(varbind[start:end])
+		oids = append(oids, oid)
+
+		value := varbind[end:]
+		tag, start, end, ok := readBERHeader(value)
+		if ok {
+			values = append(values, testVarbind{oid: oid, tag: tag, data: append([]byte{}, value[start:end]...)})
+		}
+	}
+
+	varbinds := make([]testVarbind, 0)
+
+	switch pduType {
+	case 0xa0: // GetRequest
+		for _, oid := range oids {
+			varbinds = append(varbinds, a.get(oid))
+		}
+	case 0xa1: // GetNextRequest
+		for _, oid := range oids {
+			varbinds = append(varbinds, a.getNext(oid))
+		}
+	case 0xa5: // GetBulkRequest
+		if nonRepeaters > len(oids) {
+			nonRepeaters = len(oids)
+		}
+
+		for _, oid := range oids[:nonRepeaters] {
+			varbinds = append(varbinds, a.getNext(oid))
+		}
+
+		repeaters := append([]string{}, oids[nonRepeaters:]...)
+		for r := 0; r < maxRepetitions; r++ {
+			for i, oid := range repeaters {
+				next := a.getNext(oid)
+				varbinds = append(varbinds, next)
+				repeaters[i] = next.oid
+			}
+		}
+	case 0xa3: // SetRequest
+		for _, value := range values {
+			a.set(value)
+			varbinds = append(varbinds, value)
+		}
+	default:
+		return nil, false
+	}
+
+	encodedVarbinds := make([]byte, 0)
+	for _, varbind := range varbinds {
+		encodedVarbind := append(berTLV(0x06, berOID(varbind.oid)), berTLV(varbind.tag, varbind.data)...)
+		encodedVarbinds = append(encodedVarbinds, berTLV(0x30, encodedVarbind)...)
+	}
+
+	pdu := append([]byte{}, fields[2]...)         // request-id
+	pdu = append(pdu, berTLV(0x02, []byte{0})...) // error-status
+	pdu = append(pdu, berTLV(0x02, []byte{0})...) // error-index
+	pdu = append(pdu, berTLV(0x30, encodedVarbinds)...)
+
+	message := append(append([]byte{}, fields[0]...), fields[1]...)
+	message = append(message, berTLV(0xa2, pdu)...)
+
+	return berTLV(0x30, message), true
+}
+
+func testInteger(oid string, value int64) testVarbind {
+	return testVarbind{oid: oid, tag: 0x02, data: berInteger(value)}
+}
+
+func testOctetString(oid string, value string) testVarbind {
+	return testVarbind{oid: oid, tag: 0x04, data: []byte(value)}
+}
+
+func testCounter32(oid string, value uint32) testVarbind {
+	return testVarbind{oid: oid, tag: 0x41, data: berUnsigned(uint64(value))}
+}
+
+func testTimeTicks(oid string, value uint32) testVarbind {
+	return testVarbind{oid: oid, tag: 0x43, data: berUnsigned(uint64(value))}
+}
+
+// testIfTable is sysDescr, sysUpTime and the ifIndex, ifDescr and ifInOctets columns of an ifTable with the given number of rows
+func testIfTable(rows int) []testVarbind {
+	varbinds := []testVarbind{
+		testOctetString(".1.3.6.1.2.1.1.1.0", "test agent"),
+		testTimeTicks(".1.3.6.1.2.1.1.3.0", 12345),
+	}
+
+	for row := 1; row <= rows; row++ {
+		index := strconv.Itoa(row)
+		varbinds = append(
+			varbinds,
+			testInteger(".1.3.6.1.2.1.2.2.1.1."+index, int64(row)),
+			testOctetString(".1.3.6.1.2.1.2.2.1.2."+index, "eth"+index),
+			testCounter32(".1.3.6.1.2.1.2.2.1.10."+index, uint32(row*1000)),
+		)
+	}
+
+	return varbinds
+}
@@ -0,0 +1,114 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+type batchOperation struct {
+	Operation      string   `json:"operation"` // get, getNext or getBulk
+	OIDs           []string `json:"oids"`
+	NonRepeaters   uint8    `json:"non_repeaters"`   // getBulk only
+	MaxRepetitions uint8    `json:"max_repetitions"` // getBulk only; 0 for the session default
+}
+
+type batchResult struct {
+	Results []multiResult
+	Error   string
+}
+
+func getBatchPDUType(operation string) (gosnmp.PDUType, error) {
+	switch strings.ToLower(strings.Replace(operation, "_", "", -1)) {
+	case "get":
+		return gosnmp.GetRequest, nil
+	case "getnext":
+		return gosnmp.GetNextRequest, nil
+	case "getbulk":
+		return gosnmp.GetBulkRequest, nil
+	}
+
+	return 0, fmt.Errorf("operation %#v is invalid; must be one of get, getNext or getBulk", operation)
+}
+
+// batch runs the operations (pipelined if the session permits it) and returns a batchResult for each (in the same order)
+func (s *session) batch(operations []batchOperation) []batchResult {
+	batchResults := make([]batchResult, len(operations))
+
+	requests := make([]pipelineRequest, 0)
+	requestIndexes := make([]int, 0)
+
+	for i, operation := range operations {
+		pduType, err := getBatchPDUType(operation.Operation)
+		if err != nil {
+			batchResults[i] = batchResult{make([]multiResult, 0), err.Error()}
+			continue
+		}
+
+		if len(operation.OIDs) == 0 {
+			batchResults[i] = batchResult{make([]multiResult, 0), "oids must be length of 1 or more"}
+			continue
+		}
+
+		if pduType == gosnmp.GetBulkRequest && !s.snmp.canGetBulk() {
+			batchResults[i] = batchResult{make([]multiResult, 0), "cannot call GETBULK with SNMPv1"}
+			continue
+		}
+
+		oids, err := formatOIDs(operation.OIDs)
+		if err != nil {
+			batchResults[i] = batchResult{make([]multiResult, 0), err.Error()}
+			continue
+		}
+
+		maxRepetitions := operation.MaxRepetitions
+		if maxRepetitions == 0 {
+			maxRepetitions = s.getSNMP().MaxRepetitions
+		}
+
+		requests = append(
+			requests,
+			pipelineRequest{
+				pduType:        pduType,
+				oids:           oids,
+				nonRepeaters:   operation.NonRepeaters,
+				maxRepetitions: maxRepetitions,
+			},
+		)
+		requestIndexes = append(requestIndexes, i)
+	}
+
+	for i, response := range s.snmp.pipeline(requests) {
+		index := requestIndexes[i]
+
+		if response.err != nil {
+			batchResults[index] = batchResult{make([]multiResult, 0), response.err.Error()}
+			continue
+		}
+
+		multiResults, err := buildMultiResults(requests[i].oids[0], response.result)
+		if err != nil {
+			batchResults[index] = batchResult{make([]multiResult, 0), err.Error()}
+			continue
+		}
+
+		s.annotateMultiResults(multiResults)
+
+		batchResults[index] = batchResult{multiResults, ""}
+	}
+
+	return batchResults
+}
+
+func (s *session) batchJSON(operations []batchOperation) (string, error) {
+	batchResults := s.batch(operations)
+
+	batchResultsBytes, err := json.Marshal(batchResults)
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(batchResultsBytes), nil
+}
@@ -0,0 +1,25 @@
+package gosnmp_python_go
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// listenPacket opens a UDP socket (network is udp, udp4 or udp6) on the given local address / port, optionally bound to a network interface (or VRF)
+func listenPacket(network, localAddress string, localPort int, localInterface string) (net.PacketConn, error) {
+	listenConfig := net.ListenConfig{}
+
+	if localInterface != "" {
+		listenConfig.Control = func(network, address string, c syscall.RawConn) error {
+			return bindToInterface(c, localInterface)
+		}
+	}
+
+	return listenConfig.ListenPacket(
+		context.Background(),
+		network,
+		net.JoinHostPort(localAddress, strconv.Itoa(localPort)),
+	)
+}
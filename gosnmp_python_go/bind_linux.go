@@ -0,0 +1,27 @@
+//go:build linux
+// +build linux
+
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToInterface sets SO_BINDTODEVICE on the socket; works for VRF devices too (requires CAP_NET_RAW)
+func bindToInterface(c syscall.RawConn, localInterface string) error {
+	var err error
+
+	controlErr := c.Control(func(fd uintptr) {
+		err = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, localInterface)
+	})
+	if controlErr != nil {
+		return controlErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to bind to interface %v: %v", localInterface, err)
+	}
+
+	return nil
+}
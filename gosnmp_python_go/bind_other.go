@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToInterface is only supported on Linux (SO_BINDTODEVICE)
+func bindToInterface(c syscall.RawConn, localInterface string) error {
+	return fmt.Errorf("cannot bind to interface %v; only supported on Linux", localInterface)
+}
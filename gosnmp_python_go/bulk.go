@@ -0,0 +1,129 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// bulkGroup is the results of a GetBulk for one of the requested OIDs
+type bulkGroup struct {
+	OID         string // as requested
+	NonRepeater bool
+	Results     []multiResult // one for a non-repeater; the column (up to and including any endOfMibView) for a repeater
+}
+
+// groupBulkResults de-interleaves a GetBulk response into a bulkGroup for each of the oids; the response holds a varbind for each
+// non-repeater followed by rows of a varbind for each repeater (the last row possibly cut short)
+func groupBulkResults(oids []string, nonRepeaters uint8, result *gosnmp.SnmpPacket) ([]bulkGroup, error) {
+	nonRepeaterCount := int(nonRepeaters)
+	if nonRepeaterCount > len(oids) {
+		nonRepeaterCount = len(oids)
+	}
+
+	groups := make([]bulkGroup, 0)
+	for i, oid := range oids {
+		groups = append(
+			groups,
+			bulkGroup{
+				OID:         oid,
+				NonRepeater: i < nonRepeaterCount,
+				Results:     make([]multiResult, 0),
+			},
+		)
+	}
+
+	// the agent can't find one of them (it shouldn't for a GetBulk, but some do)- if it says which, it's only that one
+	if isNoSuchNameError(result) {
+		i := int(result.ErrorIndex) - 1
+		for j := range groups {
+			if i < 0 || i >= len(groups) || i == j {
+				groups[j].Results = append(groups[j].Results, buildNoSuchInstanceMultiResult(groups[j].OID))
+			}
+		}
+
+		return groups, nil
+	}
+
+	err := checkForErrors(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Variables) > 0 {
+		err = checkForSNMPv3Issues(oids[0], result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	repeaterCount := len(oids) - nonRepeaterCount
+
+	ended := make([]bool, len(oids))
+	for k, variable := range result.Variables {
+		i := k
+		if k >= nonRepeaterCount {
+			if repeaterCount == 0 {
+				break
+			}
+
+			i = nonRepeaterCount + (k-nonRepeaterCount)%repeaterCount
+		}
+
+		// nothing after the end of the MIB is of any use
+		if ended[i] {
+			continue
+		}
+
+		multiResult, err := buildMultiResult(variable.Name, variable.Type, variable.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		groups[i].Results = append(groups[i].Results, multiResult)
+
+		if variable.Type == gosnmp.EndOfMibView {
+			ended[i] = true
+		}
+	}
+
+	return groups, nil
+}
+
+// getBulkGrouped is getBulk with the results grouped by requested OID (see groupBulkResults)
+func (s *session) getBulkGrouped(oids []string, nonRepeaters uint8, maxRepetitions uint8) ([]bulkGroup, error) {
+	if len(oids) == 0 {
+		return nil, fmt.Errorf("oids must be length of 1 or more")
+	}
+
+	oids, err := formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.snmp.getBulk(oids, nonRepeaters, maxRepetitions)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupBulkResults(oids, nonRepeaters, result)
+}
+
+func (s *session) getBulkGroupedJSON(oids []string, nonRepeaters uint8, maxRepetitions uint8) (string, error) {
+	groups, err := s.getBulkGrouped(oids, nonRepeaters, maxRepetitions)
+	if err != nil {
+		return "[]", err
+	}
+
+	for _, group := range groups {
+		s.annotateMultiResults(group.Results)
+	}
+
+	groupsBytes, err := json.Marshal(groups)
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(groupsBytes), nil
+}
@@ -0,0 +1,35 @@
+package gosnmp_python_go
+
+import (
+	"testing"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+func TestGroupBulkResultsNoSuchName(t *testing.T) {
+	oids := []string{".1.3.6.1.2.1.1.1", ".1.3.6.1.2.1.1.3", ".1.3.6.1.2.1.1.5"}
+
+	tests := []struct {
+		name       string
+		errorIndex uint8
+		expect     []int // results per group
+	}{
+		{"no index", 0, []int{1, 1, 1}},
+		{"first", 1, []int{1, 0, 0}},
+		{"last", 3, []int{0, 0, 1}},
+		{"out of range", 4, []int{1, 1, 1}},
+	}
+
+	for _, test := range tests {
+		groups, err := groupBulkResults(oids, 0, &gosnmp.SnmpPacket{Error: gosnmp.NoSuchName, ErrorIndex: test.errorIndex})
+		if err != nil {
+			t.Fatalf("%v: failed to group: %v", test.name, err)
+		}
+
+		for j, group := range groups {
+			if len(group.Results) != test.expect[j] {
+				t.Errorf("%v: expected %v results for %v, got %+v", test.name, test.expect[j], group.OID, group.Results)
+			}
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxSyntaxDepth = 16 // how many type assignments deep a syntax is followed (in case of a loop)
+
+// wellKnownDisplayHints are the DISPLAY-HINTs of common textual conventions, for when the module that defines them isn't loaded
+var wellKnownDisplayHints = map[string]string{
+	"DisplayString":   "255a",
+	"PhysAddress":     "1x:",
+	"MacAddress":      "1x:",
+	"SnmpAdminString": "255t",
+	"InetAddressIPv4": "1d.1d.1d.1d",
+	"InetAddressIPv6": "2x:2x:2x:2x:2x:2x:2x:2x",
+	"InetAddressDNS":  "255a",
+}
+
+// mibDisplay is what's needed to render the values of a node, worked out by following its syntax through any type assignments
+type mibDisplay struct {
+	base   string           // the underlying type, e.g. INTEGER, OCTET STRING or BITS
+	values []mibNamedNumber // the enumeration (or named bits), if any
+	sizes  []mibRange       // the SIZE constraint, if any
+	hint   string           // the DISPLAY-HINT, if any
+	types  []string         // the name of each type followed along the way, most specific first
+}
+
+func (d mibDisplay) hasType(name string) bool {
+	for _, typeName := range d.types {
+		if typeName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describeSyntax works out how to render the values of a node; false if it's not an object with a syntax
+func (t *mibTree) describeSyntax(node *mibNode) (mibDisplay, bool) {
+	display := mibDisplay{}
+
+	if node.Syntax == nil {
+		return display, false
+	}
+
+	moduleName := node.Module
+	syntax := node.Syntax
+
+	for depth := 0; depth < maxSyntaxDepth; depth++ {
+		if len(display.values) == 0 {
+			display.values = syntax.Values
+		}
+
+		if len(display.sizes) == 0 {
+			display.sizes = syntax.Sizes
+		}
+
+		display.base = syntax.Base
+
+		mibType, ok := t.lookupType(moduleName, syntax.Base)
+		if !ok {
+			display.types = append(display.types, syntax.Base)
+			break
+		}
+
+		display.types = append(display.types, mibType.Name)
+
+		if display.hint == "" {
+			display.hint = mibType.DisplayHint
+		}
+
+		moduleName = mibType.Module
+		syntax = &mibType.Syntax
+	}
+
+	if display.hint == "" {
+		for _, typeName := range display.types {
+			hint, ok := wellKnownDisplayHints[typeName]
+			if ok {
+				display.hint = hint
+				break
+			}
+		}
+	}
+
+	return display, true
+}
+
+// renderDisplayValue renders a value as per its syntax: a string, a list of strings (for BITS) or nil if there's nothing better than
+// the raw value
+func renderDisplayValue(display mibDisplay, result multiResult) interface{} {
+	switch result.Type {
+	case "int":
+		if len(display.values) > 0 {
+			for _, value := range display.values {
+				if value.Number == int64(result.IntValue) {
+					return value.Name
+				}
+			}
+
+			return strconv.Itoa(result.IntValue)
+		}
+
+		if display.hint != "" {
+			text, ok := renderIntegerDisplayHint(display.hint, int64(result.IntValue))
+			if ok {
+				return text
+			}
+		}
+
+	case "bytearray":
+		value := make([]byte, len(result.ByteArrayValue))
+		for i, c := range result.ByteArrayValue {
+			value[i] = byte(c)
+		}
+
+		if display.hasType("DateAndTime") {
+			text, ok := renderDateAndTime(value)
+			if ok {
+				return text
+			}
+		}
+
+		if display.hasType("InetAddress") {
+			return renderInetAddress(value)
+		}
+
+		if display.base == "BITS" {
+			return renderBits(display.values, value)
+		}
+
+		if display.hint != "" {
+			text, ok := renderOctetStringDisplayHint(display.hint, value)
+			if ok {
+				return text
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderIntegerDisplayHint renders an integer as per an RFC 2579 DISPLAY-HINT (d, d-N, x, o or b); false if the hint is invalid
+func renderIntegerDisplayHint(hint string, value int64) (string, bool) {
+	switch hint[0] {
+	case 'x':
+		return strconv.FormatInt(value, 16), len(hint) == 1
+	case 'o':
+		return strconv.FormatInt(value, 8), len(hint) == 1
+	case 'b':
+		return strconv.FormatInt(value, 2), len(hint) == 1
+	case 'd':
+	default:
+		return "", false
+	}
+
+	if len(hint) == 1 {
+		return strconv.FormatInt(value, 10), true
+	}
+
+	if hint[1] != '-' {
+		return "", false
+	}
+
+	places, err := strconv.Atoi(hint[2:])
+	if err != nil || places < 0 {
+		return "", false
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+	}
+
+	digits := strings.TrimPrefix(strconv.FormatInt(value, 10), "-")
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+
+	if places == 0 {
+		return sign + digits, true
+	}
+
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:], true
+}
+
+// octetStringHintSpec is one of the specifications that make up an RFC 2579 DISPLAY-HINT for an OCTET STRING
+type octetStringHintSpec struct {
+	repeat     bool // the first octet says how many times the spec applies
+	length     int  // octets per application
+	format     byte // x, d, o, a or t
+	separator  byte // 0 if none
+	terminator byte // 0 if none
+}
+
+func isHintDelimiter(c byte) bool {
+	return c != '*' && (c < '0' || c > '9')
+}
+
+func parseOctetStringDisplayHint(hint string) ([]octetStringHintSpec, bool) {
+	specs := make([]octetStringHintSpec, 0)
+
+	i := 0
+	for i < len(hint) {
+		spec := octetStringHintSpec{}
+
+		if hint[i] == '*' {
+			spec.repeat = true
+			i++
+		}
+
+		start := i
+		for i < len(hint) && hint[i] >= '0' && hint[i] <= '9' {
+			i++
+		}
+
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || i >= len(hint) || !strings.ContainsRune("xdoat", rune(hint[i])) {
+			return nil, false
+		}
+
+		spec.length = length
+		spec.format = hint[i]
+		i++
+
+		if i < len(hint) && isHintDelimiter(hint[i]) {
+			spec.separator = hint[i]
+			i++
+
+			if spec.repeat && i < len(hint) && isHintDelimiter(hint[i]) {
+				spec.terminator = hint[i]
+				i++
+			}
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, len(specs) > 0
+}
+
+// renderOctetStringDisplayHint renders an OCTET STRING as per an RFC 2579 DISPLAY-HINT (e.g. 1x: or 255a); the last specification
+// applies to whatever's left; false if the hint is invalid
+func renderOctetStringDisplayHint(hint string, value []byte) (string, bool) {
+	specs, ok := parseOctetStringDisplayHint(hint)
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+
+	offset := 0
+	for i := 0; offset < len(value); i++ {
+		spec := specs[len(specs)-1]
+		if i < len(specs) {
+			spec = specs[i]
+		}
+
+		count := 1
+		if spec.repeat {
+			count = int(value[offset])
+			offset++
+		}
+
+		for j := 0; j < count && offset < len(value); j++ {
+			end := offset + spec.length
+			if end > len(value) {
+				end = len(value)
+			}
+
+			field := value[offset:end]
+			offset = end
+
+			switch spec.format {
+			case 'a', 't':
+				buf.Write(field)
+			default:
+				number := uint64(0)
+				for _, c := range field {
+					number = number<<8 | uint64(c)
+				}
+
+				switch spec.format {
+				case 'x':
+					buf.WriteString(fmt.Sprintf("%0*x", len(field)*2, number))
+				case 'o':
+					buf.WriteString(strconv.FormatUint(number, 8))
+				default:
+					buf.WriteString(strconv.FormatUint(number, 10))
+				}
+			}
+
+			if offset >= len(value) {
+				break
+			}
+
+			if spec.repeat && j == count-1 && spec.terminator != 0 {
+				buf.WriteByte(spec.terminator)
+			} else if spec.separator != 0 {
+				buf.WriteByte(spec.separator)
+			}
+		}
+
+		// a zero length spec would never get anywhere
+		if spec.length == 0 && !spec.repeat {
+			break
+		}
+	}
+
+	return buf.String(), true
+}
+
+// renderDateAndTime renders a DateAndTime (as per SNMPv2-TC) as ISO 8601, e.g. 2024-01-02T03:04:05.6+10:00; false if it's the wrong
+// length
+func renderDateAndTime(value []byte) (string, bool) {
+	if len(value) != 8 && len(value) != 11 {
+		return "", false
+	}
+
+	text := fmt.Sprintf(
+		"%04d-%02d-%02dT%02d:%02d:%02d.%d",
+		binary.BigEndian.Uint16(value[0:2]),
+		value[2],
+		value[3],
+		value[4],
+		value[5],
+		value[6],
+		value[7],
+	)
+
+	if len(value) == 11 {
+		text += fmt.Sprintf("%c%02d:%02d", value[8], value[9], value[10])
+	}
+
+	return text, true
+}
+
+// renderInetAddress renders an InetAddress (as per INET-ADDRESS-MIB) by its length, as its InetAddressType is in another column;
+// text for a printable dns name (first, as a 4 or 16 octet name would otherwise pass for an address), ipv4 / ipv6 (with %zone for
+// ipv4z / ipv6z) and colon separated hex for anything else
+func renderInetAddress(value []byte) string {
+	printable := utf8.Valid(value)
+	for _, c := range value {
+		if c < 0x20 || c == 0x7f {
+			printable = false
+		}
+	}
+
+	if printable {
+		return string(value)
+	}
+
+	switch len(value) {
+	case net.IPv4len:
+		return net.IP(value).String()
+	case net.IPv6len:
+		return net.IP(value).String()
+	case net.IPv4len + 4:
+		return fmt.Sprintf("%v%%%d", net.IP(value[:net.IPv4len]), binary.BigEndian.Uint32(value[net.IPv4len:]))
+	case net.IPv6len + 4:
+		return fmt.Sprintf("%v%%%d", net.IP(value[:net.IPv6len]), binary.BigEndian.Uint32(value[net.IPv6len:]))
+	}
+
+	text, _ := renderOctetStringDisplayHint("1x:", value)
+
+	return text
+}
+
+// renderBits renders BITS as the names of the bits that are set (bit 0 is the most significant bit of the first octet); the numbers
+// of any set bits without names
+func renderBits(values []mibNamedNumber, value []byte) []string {
+	names := make(map[int64]string)
+	for _, namedNumber := range values {
+		names[namedNumber.Number] = namedNumber.Name
+	}
+
+	bits := make([]string, 0)
+	for i := 0; i < len(value)*8; i++ {
+		if value[i/8]&(0x80>>uint(i%8)) == 0 {
+			continue
+		}
+
+		name, ok := names[int64(i)]
+		if !ok {
+			name = strconv.Itoa(i)
+		}
+
+		bits = append(bits, name)
+	}
+
+	return bits
+}
@@ -0,0 +1,160 @@
+package gosnmp_python_go
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+func TestAnnotateMultiResults(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	tests := []struct {
+		oid          string
+		valueType    gosnmp.Asn1BER
+		value        interface{}
+		expectName   string
+		expectIndex  string
+		expectResult interface{}
+	}{
+		{".1.3.6.1.4.1.99999.1.0", gosnmp.Integer, -2150, "TEST-MIB::testTemperature", "0", "-21.50"},
+		{".1.3.6.1.4.1.99999.2.0", gosnmp.OctetString, []byte{0x00, 0x1b, 0x2c, 0x0a, 0x0b, 0xff}, "TEST-MIB::testMac", "0", "00:1b:2c:0a:0b:ff"},
+		{
+			".1.3.6.1.4.1.99999.3.0",
+			gosnmp.OctetString,
+			[]byte{0x07, 0xe8, 1, 2, 3, 4, 5, 6, '+', 10, 0},
+			"TEST-MIB::testTime",
+			"0",
+			"2024-01-02T03:04:05.6+10:00",
+		},
+		{".1.3.6.1.4.1.99999.4.0", gosnmp.OctetString, []byte{10, 0, 0, 1}, "TEST-MIB::testAddress", "0", "10.0.0.1"},
+		{".1.3.6.1.4.1.99999.5.0", gosnmp.OctetString, []byte{0xc0, 0x40}, "TEST-MIB::testFlags", "0", []string{"alpha", "bravo", "charlie"}},
+		{".1.3.6.1.4.1.99999.6.0", gosnmp.Integer, 1, "TEST-MIB::testTruth", "0", "true"},
+		{".1.3.6.1.4.1.99999.6.0", gosnmp.Integer, 3, "TEST-MIB::testTruth", "0", "3"},
+		{".1.3.6.1.4.1.99999.7.0", gosnmp.Integer, 3, "TEST-MIB::testHex", "0", nil},
+		{".1.3.6.1.4.1.99999.8.0", gosnmp.OctetString, []byte("hello"), "TEST-MIB::testLabel", "0", "hello"},
+		{".1.3.6.1.2.1.2.2.1.3.1", gosnmp.Integer, 6, "IF-MIB::ifType", "1", "ethernetCsmacd"},
+		{".1.3.6.1.2.1.2.2.1.6.1", gosnmp.OctetString, []byte{0, 1, 2, 3, 4, 5}, "IF-MIB::ifPhysAddress", "1", "00:01:02:03:04:05"},
+		{".1.3.6.1.2.1.2.2.1.10.1", gosnmp.Counter32, uint(5), "IF-MIB::ifInOctets", "1", nil},
+		{".1.3.6.1.4.1.12345.1", gosnmp.Integer, 1, "SNMPv2-SMI::enterprises", "12345.1", nil},
+	}
+
+	multiResults := make([]multiResult, 0)
+	for _, test := range tests {
+		multiResult, err := buildMultiResult(test.oid, test.valueType, test.value)
+		if err != nil {
+			t.Fatalf("%v: failed to build: %v", test.oid, err)
+		}
+
+		multiResults = append(multiResults, multiResult)
+	}
+
+	s := &session{annotate: true}
+	s.annotateMultiResults(multiResults)
+
+	for i, test := range tests {
+		multiResult := multiResults[i]
+
+		if multiResult.Name != test.expectName || multiResult.Index != test.expectIndex {
+			t.Errorf("%v: expected %v.%v, got %v.%v", test.oid, test.expectName, test.expectIndex, multiResult.Name, multiResult.Index)
+		}
+
+		if !reflect.DeepEqual(multiResult.DisplayValue, test.expectResult) {
+			t.Errorf("%v: expected %#v, got %#v", test.oid, test.expectResult, multiResult.DisplayValue)
+		}
+	}
+}
+
+func TestRenderIntegerDisplayHint(t *testing.T) {
+	tests := []struct {
+		hint     string
+		value    int64
+		expected string
+		ok       bool
+	}{
+		{"d", 1234, "1234", true},
+		{"d-2", 1234, "12.34", true},
+		{"d-2", 5, "0.05", true},
+		{"d-2", -5, "-0.05", true},
+		{"d-0", 7, "7", true},
+		{"x", 255, "ff", true},
+		{"o", 8, "10", true},
+		{"b", 5, "101", true},
+		{"d-", 1, "", false},
+		{"d2", 1, "", false},
+		{"x2", 1, "", false},
+		{"a", 1, "", false},
+	}
+
+	for _, test := range tests {
+		text, ok := renderIntegerDisplayHint(test.hint, test.value)
+		if ok != test.ok || (ok && text != test.expected) {
+			t.Errorf("%v of %v: expected %#v (%v), got %#v (%v)", test.hint, test.value, test.expected, test.ok, text, ok)
+		}
+	}
+}
+
+func TestRenderOctetStringDisplayHint(t *testing.T) {
+	tests := []struct {
+		hint     string
+		value    []byte
+		expected string
+		ok       bool
+	}{
+		{"1x:", []byte{0x00, 0x1b, 0xff}, "00:1b:ff", true},
+		{"255a", []byte("router1"), "router1", true},
+		{"1d.1d.1d.1d", []byte{192, 168, 0, 1}, "192.168.0.1", true},
+		{"2x:", []byte{0x20, 0x01, 0x0d, 0xb8, 0x01}, "2001:0db8:01", true},
+		{"2d-1d-1d,1d:1d:1d.1d", []byte{0x07, 0xe8, 1, 2, 3, 4, 5, 6}, "2024-1-2,3:4:5.6", true},
+		{"*1d./1x", []byte{2, 10, 20, 0xff}, "10.20/ff", true},
+		{"1o", []byte{8, 9}, "1011", true},
+		{"", []byte{1}, "", false},
+		{"1q", []byte{1}, "", false},
+		{"x", []byte{1}, "", false},
+	}
+
+	for _, test := range tests {
+		text, ok := renderOctetStringDisplayHint(test.hint, test.value)
+		if ok != test.ok || (ok && text != test.expected) {
+			t.Errorf("%v of %v: expected %#v (%v), got %#v (%v)", test.hint, test.value, test.expected, test.ok, text, ok)
+		}
+	}
+}
+
+func TestRenderInetAddress(t *testing.T) {
+	tests := []struct {
+		value    []byte
+		expected string
+	}{
+		{[]byte{10, 0, 0, 1}, "10.0.0.1"},
+		{[]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, "2001:db8::1"},
+		{[]byte{10, 0, 0, 1, 0, 0, 0, 3}, "10.0.0.1%3"},
+		{[]byte("router.example"), "router.example"},
+		{[]byte("host"), "host"},                         // the length of an ipv4 address
+		{[]byte("core1.example.au"), "core1.example.au"}, // the length of an ipv6 address
+		{[]byte("sw1.lab1"), "sw1.lab1"},                 // the length of an ipv4z address
+		{[]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 5}, "2001:db8::1%5"},
+		{[]byte{1, 2, 3}, "01:02:03"},
+	}
+
+	for _, test := range tests {
+		text := renderInetAddress(test.value)
+		if text != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.value, test.expected, text)
+		}
+	}
+}
+
+func TestRenderDateAndTime(t *testing.T) {
+	text, ok := renderDateAndTime([]byte{0x07, 0xe8, 12, 31, 23, 59, 60, 9})
+	if !ok || text != "2024-12-31T23:59:60.9" {
+		t.Errorf("expected 2024-12-31T23:59:60.9, got %v (%v)", text, ok)
+	}
+
+	_, ok = renderDateAndTime([]byte{0x07, 0xe8, 12})
+	if ok {
+		t.Errorf("expected a DateAndTime of the wrong length to be refused")
+	}
+}
@@ -0,0 +1,175 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// canGetBulk returns true if GetBulk (and bulk walks) are possible; SNMPv1 has no GetBulk unless it's being emulated
+func (w *wrappedSNMP) canGetBulk() bool {
+	return w.snmp.Version != gosnmp.Version1 || w.emulateGetBulk
+}
+
+func hasGetBulkRequest(requests []pipelineRequest) bool {
+	for _, request := range requests {
+		if request.pduType == gosnmp.GetBulkRequest {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pipelineEmulatingGetBulk is pipeline for SNMPv1; the GetBulks are emulated one after the other (each pipelining its own GetNexts,
+// see emulatedGetBulk) and everything else is pipelined as usual
+func (w *wrappedSNMP) pipelineEmulatingGetBulk(requests []pipelineRequest) []pipelineResponse {
+	responses := make([]pipelineResponse, len(requests))
+
+	otherRequests := make([]pipelineRequest, 0)
+	otherIndexes := make([]int, 0)
+
+	for i, request := range requests {
+		if request.pduType == gosnmp.GetBulkRequest {
+			responses[i].result, responses[i].err = w.getBulk(request.oids, request.nonRepeaters, request.maxRepetitions)
+			continue
+		}
+
+		otherRequests = append(otherRequests, request)
+		otherIndexes = append(otherIndexes, i)
+	}
+
+	for i, response := range w.pipeline(otherRequests) {
+		responses[otherIndexes[i]] = response
+	}
+
+	return responses
+}
+
+// emulatedGetNexts makes a GetNext for each of the oids (pipelined if the session permits it) and returns the varbind for each;
+// noSuchName (SNMPv1's way of saying there's nothing after an OID) is given as endOfMibView (named for the OID asked for) as per
+// SNMPv2c. Any other error status is returned as a packet (with ErrorIndex pointing at the offending OID's position in indexes).
+func (w *wrappedSNMP) emulatedGetNexts(oids []string, indexes []int) ([]gosnmp.SnmpPDU, *gosnmp.SnmpPacket, error) {
+	requests := make([]pipelineRequest, 0)
+	for _, oid := range oids {
+		requests = append(
+			requests,
+			pipelineRequest{
+				pduType: gosnmp.GetNextRequest,
+				oids:    []string{oid},
+			},
+		)
+	}
+
+	variables := make([]gosnmp.SnmpPDU, 0)
+
+	for i, response := range w.pipeline(requests) {
+		if response.err != nil {
+			return nil, nil, response.err
+		}
+
+		if response.result.Error == gosnmp.NoSuchName {
+			variables = append(variables, gosnmp.SnmpPDU{Name: oids[i], Type: gosnmp.EndOfMibView})
+			continue
+		}
+
+		if response.result.Error != gosnmp.NoError {
+			return nil, &gosnmp.SnmpPacket{
+				Version:    w.snmp.Version,
+				Community:  w.snmp.Community,
+				PDUType:    gosnmp.GetResponse,
+				Error:      response.result.Error,
+				ErrorIndex: uint8(indexes[i] + 1),
+				Variables:  make([]gosnmp.SnmpPDU, 0),
+			}, nil
+		}
+
+		if len(response.result.Variables) != 1 {
+			return nil, nil, fmt.Errorf("%v varbinds returned for GetNext oid=%v; expected 1", len(response.result.Variables), oids[i])
+		}
+
+		variables = append(variables, response.result.Variables[0])
+	}
+
+	return variables, nil, nil
+}
+
+// emulatedGetBulk answers a GetBulk as an SNMPv2c agent would (a varbind for each non-repeater then rows of a varbind for each
+// repeater, with endOfMibView repeated for a repeater once it's at the end) using a GetNext for each varbind; the GetNexts for each
+// row are pipelined (if the session permits it) and it stops early once all of the repeaters are at the end
+func (w *wrappedSNMP) emulatedGetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint8) (*gosnmp.SnmpPacket, error) {
+	oids, err := formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.snmp.MaxOids > 0 && len(oids) > w.snmp.MaxOids {
+		return nil, fmt.Errorf("oid count (%d) is greater than MaxOids (%d)", len(oids), w.snmp.MaxOids)
+	}
+
+	nonRepeaterCount := int(nonRepeaters)
+	if nonRepeaterCount > len(oids) {
+		nonRepeaterCount = len(oids)
+	}
+
+	result := &gosnmp.SnmpPacket{
+		Version:   w.snmp.Version,
+		Community: w.snmp.Community,
+		PDUType:   gosnmp.GetResponse,
+		Variables: make([]gosnmp.SnmpPDU, 0),
+	}
+
+	// the non-repeaters go along with the first row
+	requestOIDs := oids
+	if maxRepetitions == 0 {
+		requestOIDs = oids[:nonRepeaterCount]
+	}
+
+	requestIndexes := make([]int, 0)
+	for i := range requestOIDs {
+		requestIndexes = append(requestIndexes, i)
+	}
+
+	// where each repeater has got to (the last varbind returned for it)
+	row := make([]gosnmp.SnmpPDU, len(oids)-nonRepeaterCount)
+
+	for repetition := 0; len(requestOIDs) > 0; repetition++ {
+		variables, errorResult, err := w.emulatedGetNexts(requestOIDs, requestIndexes)
+		if err != nil {
+			return nil, err
+		}
+
+		if errorResult != nil {
+			return errorResult, nil
+		}
+
+		for k, variable := range variables {
+			i := requestIndexes[k]
+			if i < nonRepeaterCount {
+				result.Variables = append(result.Variables, variable)
+			} else {
+				row[i-nonRepeaterCount] = variable
+			}
+		}
+
+		if repetition >= int(maxRepetitions) || len(row) == 0 {
+			break
+		}
+
+		result.Variables = append(result.Variables, row...)
+
+		// and the next row is whatever comes after each of the repeaters that aren't at the end yet
+		requestOIDs = make([]string, 0)
+		requestIndexes = make([]int, 0)
+		if repetition+1 < int(maxRepetitions) {
+			for j, variable := range row {
+				if variable.Type != gosnmp.EndOfMibView {
+					requestOIDs = append(requestOIDs, variable.Name)
+					requestIndexes = append(requestIndexes, nonRepeaterCount+j)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,248 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	encodingJSON   = "json"
+	encodingBinary = "binary"
+
+	binaryEncodingVersion = 1
+
+	binaryFlagSingle    = 1 // the result is a single multiResult rather than a list of them
+	binaryFlagAnnotated = 2 // each multiResult is followed by its Name, Index and DisplayValue
+
+	binaryDisplayNone = 0
+	binaryDisplayText = 1
+	binaryDisplayList = 2
+)
+
+// binaryTypeCodes identifies each multiResult.Type in the binary encoding
+var binaryTypeCodes = map[string]uint8{
+	"noSuchInstance": 0,
+	"noSuchObject":   1,
+	"endOfMibView":   2,
+	"bool":           3,
+	"int":            4,
+	"float":          5,
+	"bytearray":      6,
+	"string":         7,
+	"null":           8,
+	"unknown":        9,
+}
+
+func normaliseEncoding(encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", encodingJSON:
+		return encodingJSON, nil
+	case encodingBinary:
+		return encodingBinary, nil
+	}
+
+	return "", fmt.Errorf("encoding %#v is invalid; must be one of json or binary", encoding)
+}
+
+// encodeBinaryMultiResults encodes multiResults in a compact length-prefixed layout (all integers big-endian), base64'd so that it
+// can cross over to Python as a string:
+//
+//	uint8 version, uint8 flags, uint32 count, then for each multiResult:
+//	    uint8 type code, uint16 OID length, OID
+//	    bool:               uint8 (0 or 1)
+//	    int:                int64
+//	    float:              float64
+//	    bytearray / string: uint32 length, bytes
+//	    unknown:            uint8 tag, uint32 length, bytes
+//	    anything else:      nothing
+//	    then, if annotated: uint16 Name length, Name, uint16 Index length, Index, uint8 DisplayValue kind (0 none, 1 text, 2 list)
+//	    text:               uint32 length, text
+//	    list:               uint16 count, then for each: uint16 length, text
+func encodeBinaryMultiResults(multiResults []multiResult, flags uint8) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte(binaryEncodingVersion)
+	buf.WriteByte(flags)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(multiResults)))
+
+	scratch := make([]byte, 8)
+
+	for _, multiResult := range multiResults {
+		typeCode, ok := binaryTypeCodes[multiResult.Type]
+		if !ok {
+			return nil, fmt.Errorf("cannot encode type %#v for %v", multiResult.Type, multiResult.OID)
+		}
+
+		if len(multiResult.OID) > math.MaxUint16 {
+			return nil, fmt.Errorf("cannot encode OID %v; too long", multiResult.OID)
+		}
+
+		buf.WriteByte(typeCode)
+		binary.BigEndian.PutUint16(scratch, uint16(len(multiResult.OID)))
+		buf.Write(scratch[:2])
+		buf.WriteString(multiResult.OID)
+
+		switch multiResult.Type {
+		case "bool":
+			if multiResult.BoolValue {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		case "int":
+			binary.BigEndian.PutUint64(scratch, uint64(multiResult.IntValue))
+			buf.Write(scratch)
+		case "float":
+			binary.BigEndian.PutUint64(scratch, math.Float64bits(multiResult.FloatValue))
+			buf.Write(scratch)
+		case "bytearray":
+			binary.BigEndian.PutUint32(scratch, uint32(len(multiResult.ByteArrayValue)))
+			buf.Write(scratch[:4])
+			for _, c := range multiResult.ByteArrayValue {
+				buf.WriteByte(byte(c))
+			}
+		case "string":
+			binary.BigEndian.PutUint32(scratch, uint32(len(multiResult.StringValue)))
+			buf.Write(scratch[:4])
+			buf.WriteString(multiResult.StringValue)
+		case "unknown":
+			buf.WriteByte(byte(multiResult.IntValue))
+			binary.BigEndian.PutUint32(scratch, uint32(len(multiResult.ByteArrayValue)))
+			buf.Write(scratch[:4])
+			for _, c := range multiResult.ByteArrayValue {
+				buf.WriteByte(byte(c))
+			}
+		}
+
+		if flags&binaryFlagAnnotated != 0 {
+			for _, text := range []string{multiResult.Name, multiResult.Index} {
+				if len(text) > math.MaxUint16 {
+					return nil, fmt.Errorf("cannot encode %v for %v; too long", text, multiResult.OID)
+				}
+
+				binary.BigEndian.PutUint16(scratch, uint16(len(text)))
+				buf.Write(scratch[:2])
+				buf.WriteString(text)
+			}
+
+			err := encodeBinaryDisplayValue(&buf, scratch, multiResult)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
+	base64.StdEncoding.Encode(encoded, buf.Bytes())
+
+	return encoded, nil
+}
+
+func encodeBinaryDisplayValue(buf *bytes.Buffer, scratch []byte, multiResult multiResult) error {
+	switch displayValue := multiResult.DisplayValue.(type) {
+	case nil:
+		buf.WriteByte(binaryDisplayNone)
+	case string:
+		buf.WriteByte(binaryDisplayText)
+		binary.BigEndian.PutUint32(scratch, uint32(len(displayValue)))
+		buf.Write(scratch[:4])
+		buf.WriteString(displayValue)
+	case []string:
+		if len(displayValue) > math.MaxUint16 {
+			return fmt.Errorf("cannot encode display value for %v; too many items", multiResult.OID)
+		}
+
+		buf.WriteByte(binaryDisplayList)
+		binary.BigEndian.PutUint16(scratch, uint16(len(displayValue)))
+		buf.Write(scratch[:2])
+
+		for _, text := range displayValue {
+			if len(text) > math.MaxUint16 {
+				return fmt.Errorf("cannot encode %v for %v; too long", text, multiResult.OID)
+			}
+
+			binary.BigEndian.PutUint16(scratch, uint16(len(text)))
+			buf.Write(scratch[:2])
+			buf.WriteString(text)
+		}
+	default:
+		return fmt.Errorf("cannot encode display value %v for %v; unknown type", displayValue, multiResult.OID)
+	}
+
+	return nil
+}
+
+// annotateMultiResults gives each of the multiResults its Name, Index and DisplayValue (from the loaded MIBs, see loadMIBs) if the
+// session annotates
+func (s *session) annotateMultiResults(multiResults []multiResult) {
+	if !s.annotate {
+		return
+	}
+
+	mibMutex.RLock()
+	defer mibMutex.RUnlock()
+
+	// a walk is mostly the same few columns over and over
+	displays := make(map[*mibNode]*mibDisplay)
+
+	for i := range multiResults {
+		node, index, ok := mibs.lookupOID(multiResults[i].OID)
+		if !ok || node.Module == "" {
+			continue
+		}
+
+		multiResults[i].Name = node.Module + "::" + node.Name
+		multiResults[i].Index = index
+
+		display, ok := displays[node]
+		if !ok {
+			nodeDisplay, ok := mibs.describeSyntax(node)
+			if ok {
+				display = &nodeDisplay
+			}
+
+			displays[node] = display
+		}
+
+		if display != nil {
+			multiResults[i].DisplayValue = renderDisplayValue(*display, multiResults[i])
+		}
+	}
+}
+
+// getBinaryFlags returns the flags describing the session's results in the binary encoding
+func (s *session) getBinaryFlags() uint8 {
+	if s.annotate {
+		return binaryFlagAnnotated
+	}
+
+	return 0
+}
+
+// marshalMultiResult encodes a multiResult as per the session's encoding
+func (s *session) marshalMultiResult(result multiResult) ([]byte, error) {
+	multiResults := []multiResult{result}
+	s.annotateMultiResults(multiResults)
+
+	if s.encoding == encodingBinary {
+		return encodeBinaryMultiResults(multiResults, binaryFlagSingle|s.getBinaryFlags())
+	}
+
+	return json.Marshal(multiResults[0])
+}
+
+// marshalMultiResults encodes multiResults as per the session's encoding
+func (s *session) marshalMultiResults(multiResults []multiResult) ([]byte, error) {
+	s.annotateMultiResults(multiResults)
+
+	if s.encoding == encodingBinary {
+		return encodeBinaryMultiResults(multiResults, s.getBinaryFlags())
+	}
+
+	return json.Marshal(multiResults)
+}
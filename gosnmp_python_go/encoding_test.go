@@ -0,0 +1,97 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+const benchmarkVarbinds = 100000
+
+// benchmarkMultiResults is a walk result of n varbinds; a mix of counters, strings, IP addresses and integers
+func benchmarkMultiResults(n int) []multiResult {
+	multiResults := make([]multiResult, 0, n)
+
+	for i := 0; i < n; i++ {
+		oid := fmt.Sprintf(".1.3.6.1.2.1.4.20.1.%d.10.%d.%d.%d", i%4+1, i/65536%256, i/256%256, i%256)
+
+		var multiResult multiResult
+		var err error
+
+		switch i % 4 {
+		case 0:
+			multiResult, err = buildMultiResult(oid, gosnmp.Counter32, uint(i*1000))
+		case 1:
+			multiResult, err = buildMultiResult(oid, gosnmp.OctetString, []byte(fmt.Sprintf("GigabitEthernet0/%d", i)))
+		case 2:
+			multiResult, err = buildMultiResult(oid, gosnmp.IPAddress, fmt.Sprintf("10.%d.%d.%d", i/65536%256, i/256%256, i%256))
+		case 3:
+			multiResult, err = buildMultiResult(oid, gosnmp.Integer, i)
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		multiResults = append(multiResults, multiResult)
+	}
+
+	return multiResults
+}
+
+// with GOSNMP_PYTHON_BENCHMARK_OUTPUT set to a directory, the benchmarks also write what they encode there (as results.json and
+// results.binary) for benchmark_decode.py to time the Python side against
+func writeBenchmarkOutput(b *testing.B, name string, encoded []byte) {
+	directory := os.Getenv("GOSNMP_PYTHON_BENCHMARK_OUTPUT")
+	if directory == "" {
+		return
+	}
+
+	err := ioutil.WriteFile(filepath.Join(directory, name), encoded, 0644)
+	if err != nil {
+		b.Fatalf("failed to write %v: %v", name, err)
+	}
+}
+
+func BenchmarkMarshalMultiResultsJSON(b *testing.B) {
+	multiResults := benchmarkMultiResults(benchmarkVarbinds)
+
+	var encoded []byte
+	var err error
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded, err = json.Marshal(multiResults)
+		if err != nil {
+			b.Fatalf("failed to encode: %v", err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(len(encoded)), "bytes")
+	writeBenchmarkOutput(b, "results.json", encoded)
+}
+
+func BenchmarkMarshalMultiResultsBinary(b *testing.B) {
+	multiResults := benchmarkMultiResults(benchmarkVarbinds)
+
+	var encoded []byte
+	var err error
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded, err = encodeBinaryMultiResults(multiResults, 0)
+		if err != nil {
+			b.Fatalf("failed to encode: %v", err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(len(encoded)), "bytes")
+	writeBenchmarkOutput(b, "results.binary", encoded)
+}
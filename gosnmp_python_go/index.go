@@ -0,0 +1,462 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	indexTypeInteger        = "integer"
+	indexTypeIPAddress      = "ipaddress"
+	indexTypeNetworkAddress = "networkaddress" // SMIv1; encoded as 1 (for internet) then the IpAddress
+	indexTypeString         = "string"
+	indexTypeOID            = "oid"
+)
+
+// indexSpecComponent is an object from an INDEX clause and how it's encoded in an instance OID (as per RFC 2578 section 7.7); it's
+// also what RPCDecodeIndex / RPCEncodeIndex receive as JSON for an inline spec
+type indexSpecComponent struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`    // integer, ipaddress, networkaddress, string or oid
+	Size    int    `json:"size"`    // string only; the fixed size (it has no length prefix), 0 if it varies
+	Implied bool   `json:"implied"` // string / oid only (and only the last); it has no length prefix and takes the rest of the OID
+}
+
+// indexComponent is a decoded component of an instance OID suffix
+type indexComponent struct {
+	Name  string
+	Type  string
+	Value interface{} // a number for an integer, a list of octets for a string, text (e.g. 10.0.0.1 or .1.3.6.1) for the others
+}
+
+func normaliseIndexType(indexType string) (string, error) {
+	switch strings.ToLower(indexType) {
+	case indexTypeInteger:
+		return indexTypeInteger, nil
+	case indexTypeIPAddress:
+		return indexTypeIPAddress, nil
+	case indexTypeNetworkAddress:
+		return indexTypeNetworkAddress, nil
+	case indexTypeString:
+		return indexTypeString, nil
+	case indexTypeOID:
+		return indexTypeOID, nil
+	}
+
+	return "", fmt.Errorf(
+		"index type %#v is invalid; must be one of %v, %v, %v, %v or %v",
+		indexType,
+		indexTypeInteger,
+		indexTypeIPAddress,
+		indexTypeNetworkAddress,
+		indexTypeString,
+		indexTypeOID,
+	)
+}
+
+// parseIndexSpec parses an inline spec, a JSON list like [{"name": "ifIndex", "type": "integer"}, {"type": "string", "implied": true}]
+func parseIndexSpec(specJSON string) ([]indexSpecComponent, error) {
+	spec := make([]indexSpecComponent, 0)
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(specJSON)))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index spec: %v", err)
+	}
+
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("index spec must be length of 1 or more")
+	}
+
+	for i := range spec {
+		spec[i].Type, err = normaliseIndexType(spec[i].Type)
+		if err != nil {
+			return nil, fmt.Errorf("index spec %v: %v", i, err)
+		}
+
+		if spec[i].Type != indexTypeString && spec[i].Size != 0 {
+			return nil, fmt.Errorf("index spec %v: size is only for a string", i)
+		}
+
+		if spec[i].Size < 0 || spec[i].Size > math.MaxUint8 {
+			return nil, fmt.Errorf("index spec %v: size %v is invalid; must be 0 to 255", i, spec[i].Size)
+		}
+
+		if spec[i].Implied && ((spec[i].Type != indexTypeString && spec[i].Type != indexTypeOID) || i != len(spec)-1) {
+			return nil, fmt.Errorf("index spec %v: only the last (and only a string or oid) can be implied", i)
+		}
+	}
+
+	return spec, nil
+}
+
+// lookupEntry finds the conceptual row that a table, row or column belongs to (the row itself for an AUGMENTS row, see indexSpec)
+func (t *mibTree) lookupEntry(node *mibNode) (*mibNode, bool) {
+	candidates := []string{node.OID, node.OID + ".1"}
+	if i := strings.LastIndexByte(node.OID, '.'); i > 0 {
+		candidates = append(candidates, node.OID[:i])
+	}
+
+	for _, oid := range candidates {
+		entry, ok := t.nodesByOID[oid]
+		if ok && (len(entry.Index) > 0 || entry.Augments != "") {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+// indexSpec works out how the rows of a table (given by the name of the table, its rows or one of its columns) are indexed
+func (t *mibTree) indexSpec(name string) ([]indexSpecComponent, error) {
+	node, ok := t.lookupNode(name)
+	if !ok {
+		return nil, fmt.Errorf("failed to find %v; unknown name", name)
+	}
+
+	entry, ok := t.lookupEntry(node)
+	if !ok {
+		return nil, fmt.Errorf("failed to find the INDEX of %v; it's not a table, row or column", name)
+	}
+
+	// a row that AUGMENTS another is indexed the same way
+	for depth := 0; entry.Augments != "" && len(entry.Index) == 0; depth++ {
+		augmented, ok := t.lookupSymbol(entry.Module, entry.Augments)
+		if !ok || depth >= maxSyntaxDepth {
+			return nil, fmt.Errorf("failed to find the INDEX of %v; %v AUGMENTS unknown %v", name, entry.Name, entry.Augments)
+		}
+
+		entry = augmented
+	}
+
+	spec := make([]indexSpecComponent, 0)
+	for _, index := range entry.Index {
+		object, ok := t.lookupSymbol(entry.Module, index.Name)
+		if !ok {
+			return nil, fmt.Errorf("failed to find the INDEX of %v; unknown index object %v", name, index.Name)
+		}
+
+		display, ok := t.describeSyntax(object)
+		if !ok {
+			return nil, fmt.Errorf("failed to find the INDEX of %v; index object %v has no SYNTAX", name, index.Name)
+		}
+
+		component := indexSpecComponent{Name: index.Name, Implied: index.Implied}
+
+		switch {
+		case display.hasType("IpAddress"):
+			component.Type = indexTypeIPAddress
+		case display.hasType("NetworkAddress"):
+			component.Type = indexTypeNetworkAddress
+		case display.base == "INTEGER":
+			component.Type = indexTypeInteger
+		case display.base == "OCTET STRING" || display.base == "BITS":
+			component.Type = indexTypeString
+			if !index.Implied && len(display.sizes) == 1 && display.sizes[0].Min == display.sizes[0].Max {
+				component.Size = int(display.sizes[0].Min)
+			}
+		case display.base == "OBJECT IDENTIFIER":
+			component.Type = indexTypeOID
+		default:
+			return nil, fmt.Errorf("failed to find the INDEX of %v; index object %v is of unsupported type %v", name, index.Name, display.base)
+		}
+
+		spec = append(spec, component)
+	}
+
+	return spec, nil
+}
+
+// getIndexSpec is the index spec of a table (by name, see indexSpec) or an inline spec (see parseIndexSpec)
+func getIndexSpec(table string) ([]indexSpecComponent, error) {
+	if strings.HasPrefix(strings.TrimSpace(table), "[") {
+		return parseIndexSpec(table)
+	}
+
+	mibMutex.RLock()
+	defer mibMutex.RUnlock()
+
+	return mibs.indexSpec(table)
+}
+
+// decodeIndex splits an instance OID suffix (e.g. 10.0.0.1.22.10.0.0.2.5000, the Index of an annotated result) into its components
+func decodeIndex(spec []indexSpecComponent, index string) ([]indexComponent, error) {
+	subIdentifiers := make([]uint64, 0)
+	for _, part := range splitOID(strings.Trim(index, ".")) {
+		if part == "" {
+			continue
+		}
+
+		subIdentifier, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("index %v is invalid; %v is not a sub-identifier", index, part)
+		}
+
+		subIdentifiers = append(subIdentifiers, subIdentifier)
+	}
+
+	// take the next n sub-identifiers (each no more than maximum)
+	take := func(component indexSpecComponent, n int, maximum uint64) ([]uint64, error) {
+		if n > len(subIdentifiers) {
+			return nil, fmt.Errorf("index %v is too short for %v", index, component.Name)
+		}
+
+		taken := subIdentifiers[:n]
+		for _, subIdentifier := range taken {
+			if subIdentifier > maximum {
+				return nil, fmt.Errorf("index %v is invalid for %v; %v is more than %v", index, component.Name, subIdentifier, maximum)
+			}
+		}
+
+		subIdentifiers = subIdentifiers[n:]
+
+		return taken, nil
+	}
+
+	// how many sub-identifiers a string / oid takes (the length prefix, if it has one, is taken)
+	length := func(component indexSpecComponent) (int, error) {
+		if component.Implied {
+			return len(subIdentifiers), nil
+		}
+
+		if component.Size > 0 {
+			return component.Size, nil
+		}
+
+		taken, err := take(component, 1, math.MaxUint32)
+		if err != nil {
+			return 0, err
+		}
+
+		return int(taken[0]), nil
+	}
+
+	components := make([]indexComponent, 0)
+	for _, component := range spec {
+		var value interface{}
+
+		switch component.Type {
+		case indexTypeInteger:
+			taken, err := take(component, 1, math.MaxUint32)
+			if err != nil {
+				return nil, err
+			}
+
+			value = taken[0]
+
+		case indexTypeIPAddress, indexTypeNetworkAddress:
+			if component.Type == indexTypeNetworkAddress {
+				taken, err := take(component, 1, math.MaxUint32)
+				if err != nil {
+					return nil, err
+				}
+
+				if taken[0] != 1 {
+					return nil, fmt.Errorf("index %v is invalid for %v; must start with 1 (internet)", index, component.Name)
+				}
+			}
+
+			taken, err := take(component, net.IPv4len, math.MaxUint8)
+			if err != nil {
+				return nil, err
+			}
+
+			value = net.IPv4(byte(taken[0]), byte(taken[1]), byte(taken[2]), byte(taken[3])).String()
+
+		case indexTypeString:
+			n, err := length(component)
+			if err != nil {
+				return nil, err
+			}
+
+			taken, err := take(component, n, math.MaxUint8)
+			if err != nil {
+				return nil, err
+			}
+
+			octets := make([]int, len(taken))
+			for i, subIdentifier := range taken {
+				octets[i] = int(subIdentifier)
+			}
+
+			value = octets
+
+		case indexTypeOID:
+			n, err := length(component)
+			if err != nil {
+				return nil, err
+			}
+
+			taken, err := take(component, n, math.MaxUint32)
+			if err != nil {
+				return nil, err
+			}
+
+			oid := ""
+			for _, subIdentifier := range taken {
+				oid += "." + strconv.FormatUint(subIdentifier, 10)
+			}
+
+			value = oid
+		}
+
+		components = append(components, indexComponent{Name: component.Name, Type: component.Type, Value: value})
+	}
+
+	if len(subIdentifiers) > 0 {
+		return nil, fmt.Errorf("index %v is too long; %v sub-identifiers left over", index, len(subIdentifiers))
+	}
+
+	return components, nil
+}
+
+// encodeIndex is the reverse of decodeIndex; each value is a number (or numeric text) for an integer, text (e.g. 10.0.0.1 or .1.3.6.1)
+// for an ipaddress, networkaddress or oid and text (each character an octet) or a list of octets for a string
+func encodeIndex(spec []indexSpecComponent, valuesJSON string) (string, error) {
+	values := make([]json.RawMessage, 0)
+
+	err := json.Unmarshal([]byte(valuesJSON), &values)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse index values: %v", err)
+	}
+
+	if len(values) != len(spec) {
+		return "", fmt.Errorf("index values must be length of %v (as per the index spec), not %v", len(spec), len(values))
+	}
+
+	subIdentifiers := make([]string, 0)
+	add := func(subIdentifier uint64) {
+		subIdentifiers = append(subIdentifiers, strconv.FormatUint(subIdentifier, 10))
+	}
+
+	for i, component := range spec {
+		raw := values[i]
+
+		var text string
+		textErr := json.Unmarshal(raw, &text)
+
+		switch component.Type {
+		case indexTypeInteger:
+			var number uint32
+			err := json.Unmarshal(raw, &number)
+			if err != nil && textErr == nil {
+				var number64 uint64
+				number64, err = strconv.ParseUint(text, 10, 32)
+				number = uint32(number64)
+			}
+
+			if err != nil {
+				return "", fmt.Errorf("index value %v for %v is invalid; must be 0 to %v", string(raw), component.Name, uint32(math.MaxUint32))
+			}
+
+			add(uint64(number))
+
+		case indexTypeIPAddress, indexTypeNetworkAddress:
+			ip := net.ParseIP(text).To4()
+			if textErr != nil || ip == nil {
+				return "", fmt.Errorf("index value %v for %v is invalid; must be an IPv4 address", string(raw), component.Name)
+			}
+
+			if component.Type == indexTypeNetworkAddress {
+				add(1)
+			}
+
+			for _, octet := range ip {
+				add(uint64(octet))
+			}
+
+		case indexTypeString:
+			octets := make([]uint8, 0)
+			if textErr == nil {
+				for _, c := range text {
+					if c > math.MaxUint8 {
+						return "", fmt.Errorf("index value %v for %v is invalid; %q is not an octet", string(raw), component.Name, c)
+					}
+
+					octets = append(octets, uint8(c))
+				}
+			} else {
+				err := json.Unmarshal(raw, &octets)
+				if err != nil {
+					return "", fmt.Errorf("index value %v for %v is invalid; must be text or a list of octets", string(raw), component.Name)
+				}
+			}
+
+			if component.Size > 0 && len(octets) != component.Size {
+				return "", fmt.Errorf("index value %v for %v is invalid; must be %v octets", string(raw), component.Name, component.Size)
+			}
+
+			if !component.Implied && component.Size == 0 {
+				add(uint64(len(octets)))
+			}
+
+			for _, octet := range octets {
+				add(uint64(octet))
+			}
+
+		case indexTypeOID:
+			parts := make([]string, 0)
+			if textErr == nil {
+				for _, part := range splitOID(strings.Trim(text, ".")) {
+					if part != "" {
+						parts = append(parts, part)
+					}
+				}
+			}
+
+			if textErr != nil || !isNumericOID(text) {
+				return "", fmt.Errorf("index value %v for %v is invalid; must be a numeric OID", string(raw), component.Name)
+			}
+
+			if !component.Implied {
+				add(uint64(len(parts)))
+			}
+
+			for _, part := range parts {
+				subIdentifier, err := strconv.ParseUint(part, 10, 32)
+				if err != nil {
+					return "", fmt.Errorf("index value %v for %v is invalid; %v is not a sub-identifier", string(raw), component.Name, part)
+				}
+
+				add(subIdentifier)
+			}
+		}
+	}
+
+	return strings.Join(subIdentifiers, "."), nil
+}
+
+func decodeIndexJSON(table string, index string) (string, error) {
+	spec, err := getIndexSpec(table)
+	if err != nil {
+		return "[]", err
+	}
+
+	components, err := decodeIndex(spec, index)
+	if err != nil {
+		return "[]", err
+	}
+
+	componentsBytes, err := json.Marshal(components)
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(componentsBytes), nil
+}
+
+func encodeIndexJSON(table string, valuesJSON string) (string, error) {
+	spec, err := getIndexSpec(table)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeIndex(spec, valuesJSON)
+}
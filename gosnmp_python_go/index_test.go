@@ -0,0 +1,124 @@
+package gosnmp_python_go
+
+import (
+	"testing"
+)
+
+func TestDecodeIndexJSON(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	tests := []struct {
+		table       string
+		index       string
+		expected    string
+		expectError string
+	}{
+		{"IF-MIB::ifTable", "3", `[{"Name":"ifIndex","Type":"integer","Value":3}]`, ""},
+		{"ifInOctets", "3", `[{"Name":"ifIndex","Type":"integer","Value":3}]`, ""},
+		{"IF-MIB::ifXEntry", "3", `[{"Name":"ifIndex","Type":"integer","Value":3}]`, ""}, // AUGMENTS ifEntry
+		{
+			"tcpConnState",
+			"10.0.0.1.22.10.0.0.2.5000",
+			`[{"Name":"tcpConnLocalAddress","Type":"ipaddress","Value":"10.0.0.1"},{"Name":"tcpConnLocalPort","Type":"integer","Value":22},` +
+				`{"Name":"tcpConnRemAddress","Type":"ipaddress","Value":"10.0.0.2"},{"Name":"tcpConnRemPort","Type":"integer","Value":5000}]`,
+			"",
+		},
+		{
+			"TEST-MIB::testNameTable",
+			"0.27.44.10.11.255.4.1.3.6.1.114.49", // a fixed size MacAddress, an OID and an IMPLIED DisplayString
+			`[{"Name":"testNameMac","Type":"string","Value":[0,27,44,10,11,255]},{"Name":"testNameOID","Type":"oid","Value":".1.3.6.1"},` +
+				`{"Name":"testNameName","Type":"string","Value":[114,49]}]`,
+			"",
+		},
+		{
+			"testNetName",
+			"1.10.0.0.1.2.104.105",
+			`[{"Name":"testNetAddress","Type":"networkaddress","Value":"10.0.0.1"},{"Name":"testNetName","Type":"string","Value":[104,105]}]`,
+			"",
+		},
+		{
+			`[{"name": "a", "type": "string", "size": 2}, {"type": "oid", "implied": true}]`,
+			"1.2.3.4",
+			`[{"Name":"a","Type":"string","Value":[1,2]},{"Name":"","Type":"oid","Value":".3.4"}]`,
+			"",
+		},
+		{"sysDescr", "0", "[]", "failed to find the INDEX of sysDescr; it's not a table, row or column"},
+		{"ifTable", "3.4", "[]", "index 3.4 is too long; 1 sub-identifiers left over"},
+		{"tcpConnTable", "10.0.0.1.22", "[]", "index 10.0.0.1.22 is too short for tcpConnRemAddress"},
+		{"ipNetToMediaTable", "2.10.0.0.256", "[]", "index 2.10.0.0.256 is invalid for ipNetToMediaNetAddress; 256 is more than 255"},
+		{"testNetTable", "2.10.0.0.1.0", "[]", "index 2.10.0.0.1.0 is invalid for testNetAddress; must start with 1 (internet)"},
+	}
+
+	for _, test := range tests {
+		decoded, err := decodeIndexJSON(test.table, test.index)
+
+		if test.expectError == "" && err != nil {
+			t.Errorf("%v %v: failed to decode: %v", test.table, test.index, err)
+		} else if test.expectError != "" && (err == nil || err.Error() != test.expectError) {
+			t.Errorf("%v %v: expected %v, got %v", test.table, test.index, test.expectError, err)
+		}
+
+		if decoded != test.expected {
+			t.Errorf("%v %v: expected %v, got %v", test.table, test.index, test.expected, decoded)
+		}
+	}
+}
+
+func TestEncodeIndexJSON(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	tests := []struct {
+		table       string
+		values      string
+		expected    string
+		expectError string
+	}{
+		{"ifTable", `[3]`, "3", ""},
+		{"tcpConnTable", `["10.0.0.1", 22, "10.0.0.2", "5000"]`, "10.0.0.1.22.10.0.0.2.5000", ""},
+		{"testNameTable", `[[0, 27, 44, 10, 11, 255], ".1.3.6.1", "r1"]`, "0.27.44.10.11.255.4.1.3.6.1.114.49", ""},
+		{"testNetTable", `["10.0.0.1", "hi"]`, "1.10.0.0.1.2.104.105", ""},
+		{"ifTable", `[3, 4]`, "", "index values must be length of 1 (as per the index spec), not 2"},
+		{"ifTable", `["x"]`, "", `index value "x" for ifIndex is invalid; must be 0 to 4294967295`},
+		{"testNameTable", `["00:1b:2c:0a:0b:ff", ".1.3.6.1", "r1"]`, "", `index value "00:1b:2c:0a:0b:ff" for testNameMac is invalid; must be 6 octets`},
+	}
+
+	for _, test := range tests {
+		encoded, err := encodeIndexJSON(test.table, test.values)
+
+		if test.expectError == "" && err != nil {
+			t.Errorf("%v %v: failed to encode: %v", test.table, test.values, err)
+		} else if test.expectError != "" && (err == nil || err.Error() != test.expectError) {
+			t.Errorf("%v %v: expected %v, got %v", test.table, test.values, test.expectError, err)
+		}
+
+		if encoded != test.expected {
+			t.Errorf("%v %v: expected %v, got %v", test.table, test.values, test.expected, encoded)
+		}
+	}
+}
+
+func TestParseIndexSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expectError string
+	}{
+		{`[{"name": "ifIndex", "type": "INTEGER"}]`, ""},
+		{`[]`, "index spec must be length of 1 or more"},
+		{`[{"type": "float"}]`, `index spec 0: index type "float" is invalid; must be one of integer, ipaddress, networkaddress, string or oid`},
+		{`[{"type": "integer", "size": 4}]`, "index spec 0: size is only for a string"},
+		{`[{"type": "string", "implied": true}, {"type": "integer"}]`, "index spec 0: only the last (and only a string or oid) can be implied"},
+		{`[{"type": "string", "length": 4}]`, `failed to parse index spec: json: unknown field "length"`},
+	}
+
+	for _, test := range tests {
+		_, err := parseIndexSpec(test.spec)
+
+		if test.expectError == "" && err != nil {
+			t.Errorf("%v: failed to parse: %v", test.spec, err)
+		} else if test.expectError != "" && (err == nil || err.Error() != test.expectError) {
+			t.Errorf("%v: expected %v, got %v", test.spec, test.expectError, err)
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// builtinMIBs are the base modules (as far as OIDs and types go) so that modules can be loaded without them; loading a module of
+// the same name replaces them
+const builtinMIBs = `
+RFC1155-SMI DEFINITIONS ::= BEGIN
+internet     OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 }
+directory    OBJECT IDENTIFIER ::= { internet 1 }
+mgmt         OBJECT IDENTIFIER ::= { internet 2 }
+experimental OBJECT IDENTIFIER ::= { internet 3 }
+private      OBJECT IDENTIFIER ::= { internet 4 }
+enterprises  OBJECT IDENTIFIER ::= { private 1 }
+NetworkAddress ::= CHOICE { internet IpAddress }
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+Gauge ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+TimeTicks ::= [APPLICATION 3] IMPLICIT INTEGER (0..4294967295)
+Opaque ::= [APPLICATION 4] IMPLICIT OCTET STRING
+END
+
+SNMPv2-SMI DEFINITIONS ::= BEGIN
+org          OBJECT IDENTIFIER ::= { iso 3 }
+dod          OBJECT IDENTIFIER ::= { org 6 }
+internet     OBJECT IDENTIFIER ::= { dod 1 }
+directory    OBJECT IDENTIFIER ::= { internet 1 }
+mgmt         OBJECT IDENTIFIER ::= { internet 2 }
+mib-2        OBJECT IDENTIFIER ::= { mgmt 1 }
+transmission OBJECT IDENTIFIER ::= { mib-2 10 }
+experimental OBJECT IDENTIFIER ::= { internet 3 }
+private      OBJECT IDENTIFIER ::= { internet 4 }
+enterprises  OBJECT IDENTIFIER ::= { private 1 }
+security     OBJECT IDENTIFIER ::= { internet 5 }
+snmpV2       OBJECT IDENTIFIER ::= { internet 6 }
+snmpDomains  OBJECT IDENTIFIER ::= { snmpV2 1 }
+snmpProxys   OBJECT IDENTIFIER ::= { snmpV2 2 }
+snmpModules  OBJECT IDENTIFIER ::= { snmpV2 3 }
+zeroDotZero  OBJECT IDENTIFIER ::= { 0 0 }
+Integer32 ::= INTEGER (-2147483648..2147483647)
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter32 ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+Gauge32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+Unsigned32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+TimeTicks ::= [APPLICATION 3] IMPLICIT INTEGER (0..4294967295)
+Opaque ::= [APPLICATION 4] IMPLICIT OCTET STRING
+Counter64 ::= [APPLICATION 6] IMPLICIT INTEGER (0..18446744073709551615)
+END
+`
+
+// mibRoots are the nodes that every OID hangs off
+var mibRoots = map[string]string{
+	"ccitt":           ".0",
+	"iso":             ".1",
+	"joint-iso-ccitt": ".2",
+}
+
+// mibNode is a named OID from a loaded module
+type mibNode struct {
+	Module   string
+	Name     string
+	OID      string
+	Kind     string // OBJECT IDENTIFIER, OBJECT-TYPE, MODULE-IDENTITY, NOTIFICATION-TYPE etc
+	Syntax   *mibSyntax
+	Access   string
+	Status   string
+	Units    string
+	Index    []mibIndex
+	Augments string
+}
+
+// mibType is a type assignment (or TEXTUAL-CONVENTION) from a loaded module
+type mibType struct {
+	Module      string
+	Name        string
+	Syntax      mibSyntax
+	DisplayHint string
+	IsTC        bool
+}
+
+type mibModule struct {
+	definition *mibModuleDefinition
+	nodes      map[string]*mibNode
+	types      map[string]*mibType
+}
+
+// mibTree is every loaded module, with their OIDs resolved
+type mibTree struct {
+	definitions map[string]*mibModuleDefinition
+	modules     map[string]*mibModule
+	nodesByOID  map[string]*mibNode
+	nodesByName map[string][]*mibNode // for names given without their module
+	unresolved  []string              // MODULE::name of each definition whose OID couldn't be worked out
+}
+
+// mibLoadResult is what RPCLoadMIBs returns
+type mibLoadResult struct {
+	Modules    []string // the modules loaded (by name)
+	Errors     []string // files that couldn't be parsed (and why)
+	Unresolved []string // MODULE::name of each definition (across all modules) whose OID couldn't be worked out
+}
+
+var mibMutex sync.RWMutex
+var mibs = newMIBTree()
+
+// newMIBTree returns a tree of just the built-in modules; it panics if builtinMIBs doesn't parse, as it's run at init and that can
+// only be a bug (see TestBuiltinMIBs)
+func newMIBTree() *mibTree {
+	t := &mibTree{
+		definitions: make(map[string]*mibModuleDefinition),
+	}
+
+	definitions, err := parseSMI(builtinMIBs)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, definition := range definitions {
+		t.definitions[definition.Name] = definition
+	}
+
+	t.build()
+
+	return t
+}
+
+// build works out the nodes and types of every module from their definitions
+func (t *mibTree) build() {
+	t.modules = make(map[string]*mibModule)
+	t.nodesByOID = make(map[string]*mibNode)
+	t.nodesByName = make(map[string][]*mibNode)
+	t.unresolved = make([]string, 0)
+
+	moduleNames := make([]string, 0)
+	for name := range t.definitions {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+
+	for _, name := range moduleNames {
+		definition := t.definitions[name]
+
+		module := &mibModule{
+			definition: definition,
+			nodes:      make(map[string]*mibNode),
+			types:      make(map[string]*mibType),
+		}
+
+		for _, typeDefinition := range definition.Types {
+			module.types[typeDefinition.Name] = &mibType{
+				Module:      name,
+				Name:        typeDefinition.Name,
+				Syntax:      typeDefinition.Syntax,
+				DisplayHint: typeDefinition.DisplayHint,
+				IsTC:        typeDefinition.IsTC,
+			}
+		}
+
+		t.modules[name] = module
+	}
+
+	// definitions can refer to those that come after them (and in other modules), so keep going until nothing more resolves
+	for {
+		progress := false
+
+		for _, name := range moduleNames {
+			module := t.modules[name]
+
+			for i := range module.definition.Definitions {
+				definition := &module.definition.Definitions[i]
+				if _, ok := module.nodes[definition.Name]; ok {
+					continue
+				}
+
+				oid, ok := t.resolveValue(name, definition.Value)
+				if !ok {
+					continue
+				}
+
+				node := &mibNode{
+					Module:   name,
+					Name:     definition.Name,
+					OID:      oid,
+					Kind:     definition.Kind,
+					Syntax:   definition.Syntax,
+					Access:   definition.Access,
+					Status:   definition.Status,
+					Units:    definition.Units,
+					Index:    definition.Index,
+					Augments: definition.Augments,
+				}
+
+				module.nodes[definition.Name] = node
+				t.nodesByName[definition.Name] = append(t.nodesByName[definition.Name], node)
+
+				// the first module (by name) to define an OID gets to name it, unless a module of a lower rank (see mibModuleRank) does too
+				existing, ok := t.nodesByOID[oid]
+				if !ok || mibModuleRank(name) < mibModuleRank(existing.Module) {
+					t.nodesByOID[oid] = node
+				}
+
+				progress = true
+			}
+		}
+
+		if !progress {
+			break
+		}
+	}
+
+	for _, name := range moduleNames {
+		module := t.modules[name]
+
+		for _, definition := range module.definition.Definitions {
+			if _, ok := module.nodes[definition.Name]; !ok {
+				t.unresolved = append(t.unresolved, name+"::"+definition.Name)
+			}
+		}
+	}
+}
+
+// mibModuleRank is how readily a module gets to name an OID that others also define (lowest first); the builtin modules come last,
+// SMIv1 after SMIv2
+func mibModuleRank(name string) int {
+	switch name {
+	case "SNMPv2-SMI":
+		return 1
+	case "RFC1155-SMI":
+		return 2
+	}
+
+	return 0
+}
+
+// lookupSymbol finds a node by the name it's known by within a module (its own, then those it imports, then anything)
+func (t *mibTree) lookupSymbol(moduleName string, name string) (*mibNode, bool) {
+	module, ok := t.modules[moduleName]
+	if ok {
+		node, ok := module.nodes[name]
+		if ok {
+			return node, true
+		}
+
+		from, ok := module.definition.Imports[name]
+		if ok {
+			fromModule, ok := t.modules[from]
+			if ok {
+				node, ok := fromModule.nodes[name]
+				if ok {
+					return node, true
+				}
+			}
+		}
+	}
+
+	// the module it's imported from may not be loaded (or the import was left out); any with the same name will do
+	nodes := t.nodesByName[name]
+	if len(nodes) > 0 {
+		return nodes[0], true
+	}
+
+	return nil, false
+}
+
+// lookupType finds a type by the name it's known by within a module (its own, then those it imports, then anything)
+func (t *mibTree) lookupType(moduleName string, name string) (*mibType, bool) {
+	module, ok := t.modules[moduleName]
+	if ok {
+		mibType, ok := module.types[name]
+		if ok {
+			return mibType, true
+		}
+
+		from, ok := module.definition.Imports[name]
+		if ok {
+			fromModule, ok := t.modules[from]
+			if ok {
+				mibType, ok := fromModule.types[name]
+				if ok {
+					return mibType, true
+				}
+			}
+		}
+	}
+
+	moduleNames := make([]string, 0)
+	for name := range t.modules {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+
+	for _, otherModuleName := range moduleNames {
+		mibType, ok := t.modules[otherModuleName].types[name]
+		if ok {
+			return mibType, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveValue works out the OID of an OBJECT IDENTIFIER value (false if it refers to something that's not resolved yet)
+func (t *mibTree) resolveValue(moduleName string, value []mibOIDElement) (string, bool) {
+	oid := ""
+
+	for i, element := range value {
+		if i == 0 && element.Number < 0 {
+			root, ok := mibRoots[element.Name]
+			if ok {
+				oid = root
+				continue
+			}
+
+			node, ok := t.lookupSymbol(moduleName, element.Name)
+			if !ok {
+				return "", false
+			}
+
+			oid = node.OID
+			continue
+		}
+
+		if element.Number < 0 {
+			return "", false
+		}
+
+		oid += "." + strconv.FormatInt(element.Number, 10)
+	}
+
+	return oid, oid != ""
+}
+
+// lookupNode finds a node by name; MODULE::name or just the name
+func (t *mibTree) lookupNode(name string) (*mibNode, bool) {
+	parts := strings.SplitN(name, "::", 2)
+	if len(parts) == 2 {
+		module, ok := t.modules[parts[0]]
+		if !ok {
+			return nil, false
+		}
+
+		node, ok := module.nodes[parts[1]]
+
+		return node, ok
+	}
+
+	root, ok := mibRoots[name]
+	if ok {
+		return &mibNode{Name: name, OID: root}, true
+	}
+
+	return t.lookupSymbol("", name)
+}
+
+// lookupOID finds the node that's the longest prefix of an OID and returns it along with the rest of the OID (the index, for a
+// column)
+func (t *mibTree) lookupOID(oid string) (*mibNode, string, bool) {
+	oid = formatNumericOID(oid)
+
+	prefix := oid
+	for {
+		node, ok := t.nodesByOID[prefix]
+		if ok {
+			return node, strings.TrimPrefix(oid[len(prefix):], "."), true
+		}
+
+		i := strings.LastIndexByte(prefix, '.')
+		if i <= 0 {
+			return nil, "", false
+		}
+
+		prefix = prefix[:i]
+	}
+}
+
+// isNumericOID returns true if the OID is just numbers and dots (rather than a name)
+func isNumericOID(oid string) bool {
+	for i := 0; i < len(oid); i++ {
+		if (oid[i] < '0' || oid[i] > '9') && oid[i] != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveOIDName turns a name like IF-MIB::ifInOctets.3 (or ifInOctets.3, or just ifInOctets) into a numeric OID
+func resolveOIDName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	// the name is everything up to the first dot after any module
+	start := 0
+	if i := strings.Index(name, "::"); i >= 0 {
+		start = i + 2
+	}
+
+	suffix := ""
+	if i := strings.IndexByte(name[start:], '.'); i >= 0 {
+		suffix = name[start+i:]
+		name = name[:start+i]
+	}
+
+	if !isNumericOID(suffix) {
+		return "", fmt.Errorf("failed to resolve %v%v; the index %v must be numeric", name, suffix, suffix)
+	}
+
+	mibMutex.RLock()
+	node, ok := mibs.lookupNode(name)
+	mibMutex.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("failed to resolve %v%v; unknown name %v", name, suffix, name)
+	}
+
+	return node.OID + strings.TrimRight(suffix, "."), nil
+}
+
+// lookupOIDName turns a numeric OID into its name (as MODULE::name) and index; false if it's not under any loaded node
+func lookupOIDName(oid string) (string, string, bool) {
+	mibMutex.RLock()
+	defer mibMutex.RUnlock()
+
+	node, index, ok := mibs.lookupOID(oid)
+	if !ok || node.Module == "" {
+		return "", "", false
+	}
+
+	return node.Module + "::" + node.Name, index, true
+}
+
+// formatOIDName is lookupOIDName as a single string, e.g. IF-MIB::ifInOctets.3 (the OID as is if it's not under any loaded node)
+func formatOIDName(oid string) string {
+	name, index, ok := lookupOIDName(oid)
+	if !ok {
+		return formatNumericOID(oid)
+	}
+
+	if index == "" {
+		return name
+	}
+
+	return name + "." + index
+}
+
+// loadMIBs parses every file in the directory and adds the modules they hold (replacing any already loaded of the same name)
+func loadMIBs(directory string) (mibLoadResult, error) {
+	result := mibLoadResult{
+		Modules:    make([]string, 0),
+		Errors:     make([]string, 0),
+		Unresolved: make([]string, 0),
+	}
+
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return result, fmt.Errorf("failed to read MIB directory: %v", err)
+	}
+
+	definitions := make([]*mibModuleDefinition, 0)
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(directory, file.Name())
+
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%v: %v", path, err))
+			continue
+		}
+
+		fileDefinitions, err := parseSMI(string(text))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%v: %v", path, err))
+			continue
+		}
+
+		definitions = append(definitions, fileDefinitions...)
+	}
+
+	mibMutex.Lock()
+	defer mibMutex.Unlock()
+
+	for _, definition := range definitions {
+		mibs.definitions[definition.Name] = definition
+		result.Modules = append(result.Modules, definition.Name)
+	}
+
+	mibs.build()
+
+	sort.Strings(result.Modules)
+	result.Unresolved = append(result.Unresolved, mibs.unresolved...)
+
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package gosnmp_python_go
+
+import (
+	"strings"
+	"testing"
+)
+
+// loadTestMIBs loads the modules in testdata/mibs (IF-MIB and friends, plus TEST-MIB which has a bit of everything) into the
+// process-wide tree; call the returned func to put the tree back to just the built-in modules
+func loadTestMIBs(t *testing.T) (mibLoadResult, func()) {
+	result, err := loadMIBs("testdata/mibs")
+	if err != nil {
+		t.Fatalf("failed to load MIBs: %v", err)
+	}
+
+	return result, func() {
+		mibMutex.Lock()
+		mibs = newMIBTree()
+		mibMutex.Unlock()
+	}
+}
+
+// newMIBTree parses builtinMIBs at init (and panics if it can't)
+func TestBuiltinMIBs(t *testing.T) {
+	_, err := parseSMI(builtinMIBs)
+	if err != nil {
+		t.Fatalf("failed to parse builtinMIBs: %v", err)
+	}
+
+	tree := newMIBTree()
+
+	if len(tree.unresolved) != 0 {
+		t.Errorf("expected every built-in definition to resolve, got %v unresolved", tree.unresolved)
+	}
+
+	for name, expected := range map[string]string{
+		"SNMPv2-SMI::enterprises": ".1.3.6.1.4.1",
+		"RFC1155-SMI::mgmt":       ".1.3.6.1.2",
+		"mib-2":                   ".1.3.6.1.2.1",
+		"zeroDotZero":             ".0.0",
+	} {
+		node, ok := tree.lookupNode(name)
+		if !ok {
+			t.Errorf("failed to look up %v", name)
+			continue
+		}
+
+		if node.OID != expected {
+			t.Errorf("expected %v to be %v, got %v", name, expected, node.OID)
+		}
+	}
+}
+
+func TestLoadMIBs(t *testing.T) {
+	result, restore := loadTestMIBs(t)
+	defer restore()
+
+	expectedModules := "IANAifType-MIB IF-MIB INET-ADDRESS-MIB RFC1213-MIB SNMPv2-MIB SNMPv2-TC TEST-MIB"
+	if strings.Join(result.Modules, " ") != expectedModules {
+		t.Errorf("expected modules %v, got %v", expectedModules, result.Modules)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "NOT-A-MIB.txt") {
+		t.Errorf("expected an error for NOT-A-MIB.txt only, got %v", result.Errors)
+	}
+
+	if len(result.Unresolved) != 0 {
+		t.Errorf("expected every definition to resolve, got %v unresolved", result.Unresolved)
+	}
+}
+
+func TestResolveOIDName(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	tests := []struct {
+		name        string
+		expectOID   string
+		expectError string
+	}{
+		{"SNMPv2-SMI::enterprises", ".1.3.6.1.4.1", ""},
+		{"IF-MIB::ifInOctets.3", ".1.3.6.1.2.1.2.2.1.10.3", ""},
+		{"ifInOctets.3", ".1.3.6.1.2.1.2.2.1.10.3", ""},
+		{" sysDescr.0 ", ".1.3.6.1.2.1.1.1.0", ""},
+		{"TEST-MIB::testMIB", ".1.3.6.1.4.1.99999", ""},
+		{"IF-MIB::ifInOctets.three", "", "failed to resolve IF-MIB::ifInOctets.three; the index .three must be numeric"},
+		{"IF-MIB::sysDescr", "", "failed to resolve IF-MIB::sysDescr; unknown name IF-MIB::sysDescr"},
+		{"noSuchObject.1", "", "failed to resolve noSuchObject.1; unknown name noSuchObject"},
+	}
+
+	for _, test := range tests {
+		oid, err := resolveOIDName(test.name)
+
+		if test.expectError != "" {
+			if err == nil || err.Error() != test.expectError {
+				t.Errorf("%v: expected %v, got %v", test.name, test.expectError, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v: failed to resolve: %v", test.name, err)
+			continue
+		}
+
+		if oid != test.expectOID {
+			t.Errorf("%v: expected %v, got %v", test.name, test.expectOID, oid)
+		}
+	}
+}
+
+func TestFormatOIDName(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	for oid, expected := range map[string]string{
+		".1.3.6.1.2.1.2.2.1.10.3":  "IF-MIB::ifInOctets.3",
+		".1.3.6.1.2.1.1.1.0":       "SNMPv2-MIB::sysDescr.0",
+		".1.3.6.1.4.1.99999.1":     "TEST-MIB::testTemperature",
+		".1.3.6.1.4.1.12345.1.2.3": "SNMPv2-SMI::enterprises.12345.1.2.3",
+	} {
+		name := formatOIDName(oid)
+		if name != expected {
+			t.Errorf("expected %v to be %v, got %v", oid, expected, name)
+		}
+	}
+}
+
+func TestUnresolvedNamesFail(t *testing.T) {
+	_, restore := loadTestMIBs(t)
+	defer restore()
+
+	a := newTestAgent(t, testIfTable(5))
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	const expected = "failed to resolve IF-MIB::ifDescrr; unknown name IF-MIB::ifDescrr"
+
+	s, _ := getSession(sessionID)
+
+	multiResults, err := s.walk("IF-MIB::ifDescr", walkOptions{})
+	if err != nil || len(multiResults) != 5 {
+		t.Errorf("expected 5 results walking IF-MIB::ifDescr, got %v (%v)", len(multiResults), err)
+	}
+
+	_, err = s.get("IF-MIB::ifDescrr.1")
+	if err == nil || err.Error() != "failed to resolve IF-MIB::ifDescrr.1; unknown name IF-MIB::ifDescrr" {
+		t.Errorf("get: expected the name to fail to resolve, got %v", err)
+	}
+
+	for way, err := range walkAllWays(t, sessionID, "IF-MIB::ifDescrr", walkOptions{}, 0) {
+		if err == nil || err.Error() != expected {
+			t.Errorf("%v: expected %v, got %v", way, expected, err)
+		}
+	}
+
+	for _, options := range []string{`{"stop_oid": "IF-MIB::ifDescrr"}`, `{"exclude": ["IF-MIB::ifDescrr"]}`, `{"resume_oid": "IF-MIB::ifDescrr"}`} {
+		_, err = parseWalkOptions(options)
+		if err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("%v: expected %v, got %v", options, expected, err)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPort      = 161
+	defaultTimeout   = 5.0
+	defaultRetries   = 1
+	defaultTransport = "udp"
+
+	defaultMaxMessageSize = 1472  // what fits in a single Ethernet frame (as per net-snmp)
+	minMaxMessageSize     = 484   // the least an SNMP entity must accept (RFC 3417)
+	maxMaxMessageSize     = 65507 // the most that fits in a UDP datagram
+)
+
+// sessionOptions describes everything needed to build a session; it's what NewRPCSessionFromOptions receives as JSON
+type sessionOptions struct {
+	Version          string      `json:"version"`
+	Hostname         string      `json:"hostname"`
+	Port             int         `json:"port"`
+	Transport        string      `json:"transport"`
+	Community        string      `json:"community"`
+	ContextName      string      `json:"context_name"`
+	SecurityUsername string      `json:"security_username"`
+	SecurityLevel    string      `json:"security_level"`
+	AuthPassword     string      `json:"auth_password"`
+	AuthProtocol     string      `json:"auth_protocol"`
+	PrivacyPassword  string      `json:"privacy_password"`
+	PrivacyProtocol  string      `json:"privacy_protocol"`
+	Timeout          float64     `json:"timeout"` // seconds, fractions permitted (e.g. 0.2)
+	Retries          int         `json:"retries"`
+	MaxOids          int         `json:"max_oids"`
+	MaxRepetitions   int         `json:"max_repetitions"` // 0 for defaultMaxRepetitions, or what's been learned about the device (see targetProfile)
+	LocalAddress     string      `json:"local_address"`
+	LocalPort        int         `json:"local_port"`
+	LocalInterface   string      `json:"local_interface"`
+	Logging          bool        `json:"logging"`
+	RetryPolicy      retryPolicy `json:"retry_policy"`
+	PipelineDepth    int         `json:"pipeline_depth"`   // how many requests RPCBatch may have in flight at once (SNMPv1 / SNMPv2c)
+	Encoding         string      `json:"encoding"`         // how results are returned; json or binary (see encodeBinaryMultiResults)
+	MaxMessageSize   int         `json:"max_message_size"` // the most bytes a GetBulk response should take (see getFittingMaxRepetitions)
+	EmulateGetBulk   bool        `json:"emulate_getbulk"`  // SNMPv1 only; GetBulk (and bulk walks) are emulated with GetNext (see emulatedGetBulk)
+	Annotate         bool        `json:"annotate"`         // results are given their symbolic names (see annotateMultiResults)
+}
+
+func getDefaultSessionOptions() sessionOptions {
+	return sessionOptions{
+		Version:        "2c",
+		Port:           defaultPort,
+		Transport:      defaultTransport,
+		Timeout:        defaultTimeout,
+		Retries:        defaultRetries,
+		MaxOids:        maxOids,
+		PipelineDepth:  1,
+		Encoding:       encodingJSON,
+		MaxMessageSize: defaultMaxMessageSize,
+	}
+}
+
+// normaliseVersion turns "v2c", "2C", "2" etc into one of "1", "2c" or "3"
+func normaliseVersion(version string) (string, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v") {
+	case "1":
+		return "1", nil
+	case "2", "2c":
+		return "2c", nil
+	case "3":
+		return "3", nil
+	}
+
+	return "", fmt.Errorf("version %#v is invalid; must be one of 1, 2c or 3", version)
+}
+
+// secondsToDuration converts (possibly fractional) seconds to a time.Duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (o *sessionOptions) getTimeout() time.Duration {
+	return secondsToDuration(o.Timeout)
+}
+
+func (o *sessionOptions) getProtocolName() string {
+	return fmt.Sprintf("SNMPv%v", o.Version)
+}
+
+// validate normalises the options in-place and returns an error describing the first problem found
+func (o *sessionOptions) validate() error {
+	var err error
+
+	o.Version, err = normaliseVersion(o.Version)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(o.Hostname) == "" {
+		return fmt.Errorf("hostname must not be empty")
+	}
+
+	if o.Port < 1 || o.Port > 65535 {
+		return fmt.Errorf("port %v is invalid; must be between 1 and 65535", o.Port)
+	}
+
+	o.Transport = strings.ToLower(o.Transport)
+	switch o.Transport {
+	case "udp", "udp4", "udp6":
+	default:
+		return fmt.Errorf("transport %#v is invalid; must be one of udp, udp4 or udp6", o.Transport)
+	}
+
+	if o.Timeout <= 0 {
+		return fmt.Errorf("timeout %v is invalid; must be greater than 0", o.Timeout)
+	}
+
+	if o.getTimeout() < time.Millisecond {
+		return fmt.Errorf("timeout %v is invalid; must be at least 1ms", o.Timeout)
+	}
+
+	if o.Retries < 0 {
+		return fmt.Errorf("retries %v is invalid; must not be negative", o.Retries)
+	}
+
+	if o.MaxOids < 1 {
+		return fmt.Errorf("max_oids %v is invalid; must be greater than 0", o.MaxOids)
+	}
+
+	if o.MaxRepetitions < 0 || o.MaxRepetitions > 255 {
+		return fmt.Errorf("max_repetitions %v is invalid; must be between 1 and 255 (or 0 for the default)", o.MaxRepetitions)
+	}
+
+	if o.LocalPort < 0 || o.LocalPort > 65535 {
+		return fmt.Errorf("local_port %v is invalid; must be between 0 and 65535", o.LocalPort)
+	}
+
+	if o.PipelineDepth < 1 {
+		return fmt.Errorf("pipeline_depth %v is invalid; must be greater than 0", o.PipelineDepth)
+	}
+
+	if o.MaxMessageSize < minMaxMessageSize || o.MaxMessageSize > maxMaxMessageSize {
+		return fmt.Errorf("max_message_size %v is invalid; must be between %v and %v", o.MaxMessageSize, minMaxMessageSize, maxMaxMessageSize)
+	}
+
+	o.Encoding, err = normaliseEncoding(o.Encoding)
+	if err != nil {
+		return err
+	}
+
+	err = o.RetryPolicy.validate()
+	if err != nil {
+		return err
+	}
+
+	if o.Version != "3" {
+		return nil
+	}
+
+	if o.SecurityUsername == "" {
+		return fmt.Errorf("security_username must not be empty for SNMPv3")
+	}
+
+	switch strings.ToLower(o.SecurityLevel) {
+	case "noauthnopriv":
+		return nil
+	case "authnopriv", "authpriv":
+	default:
+		return fmt.Errorf("security_level %#v is invalid; must be one of noAuthNoPriv, authNoPriv or authPriv", o.SecurityLevel)
+	}
+
+	o.AuthProtocol = strings.ToUpper(o.AuthProtocol)
+	switch o.AuthProtocol {
+	case "MD5", "SHA":
+	default:
+		return fmt.Errorf("auth_protocol %#v is invalid for security_level %v; must be one of MD5 or SHA", o.AuthProtocol, o.SecurityLevel)
+	}
+
+	// RFC 3414 section 11.2
+	if len(o.AuthPassword) < 8 {
+		return fmt.Errorf("auth_password must be at least 8 characters")
+	}
+
+	if strings.ToLower(o.SecurityLevel) != "authpriv" {
+		return nil
+	}
+
+	o.PrivacyProtocol = strings.ToUpper(o.PrivacyProtocol)
+	switch o.PrivacyProtocol {
+	case "DES", "AES":
+	default:
+		return fmt.Errorf("privacy_protocol %#v is invalid for security_level %v; must be one of DES or AES", o.PrivacyProtocol, o.SecurityLevel)
+	}
+
+	if len(o.PrivacyPassword) < 8 {
+		return fmt.Errorf("privacy_password must be at least 8 characters")
+	}
+
+	return nil
+}
+
+// parseSessionOptions parses and validates a JSON options document, filling in defaults for anything not given
+func parseSessionOptions(optionsJSON string) (sessionOptions, error) {
+	options := getDefaultSessionOptions()
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(optionsJSON)))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&options)
+	if err != nil {
+		return sessionOptions{}, fmt.Errorf("failed to parse session options: %v", err)
+	}
+
+	err = options.validate()
+	if err != nil {
+		return sessionOptions{}, err
+	}
+
+	return options, nil
+}
+
+// parseSessionURI parses and validates a URI in the style of RFC 4088, e.g.:
+//
+//	snmp://public@10.0.0.1?version=2c&timeout=0.5
+//	snmp://admin@[fe80::1]:1161/someContext?auth=SHA&auth_password=secret123&privacy=AES&privacy_password=secret456
+//
+// The user part is the community for SNMPv1 / SNMPv2c and the security username for SNMPv3; the path (if any) is the context name.
+//
+// Query parameters are named the same as the options JSON fields; "auth" and "privacy" are accepted as shorthand for
+// "auth_protocol" and "privacy_protocol" and "context" for "context_name" and the retry_policy fields are given as retry_mode,
+// retry_delay, retry_max_delay, retry_multiplier and retry_jitter. If version isn't given it's SNMPv3 when any auth /
+// privacy parameters are present and SNMPv2c otherwise, and if security_level isn't given it's inferred from the protocols.
+func parseSessionURI(uri string) (sessionOptions, error) {
+	options := getDefaultSessionOptions()
+
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return sessionOptions{}, fmt.Errorf("failed to parse session URI: %v", err)
+	}
+
+	if strings.ToLower(parsedURI.Scheme) != "snmp" {
+		return sessionOptions{}, fmt.Errorf("session URI scheme %#v is invalid; must be snmp", parsedURI.Scheme)
+	}
+
+	options.Hostname = parsedURI.Hostname()
+
+	if parsedURI.Port() != "" {
+		options.Port, err = strconv.Atoi(parsedURI.Port())
+		if err != nil {
+			return sessionOptions{}, fmt.Errorf("port %#v is invalid: %v", parsedURI.Port(), err)
+		}
+	}
+
+	user := ""
+	if parsedURI.User != nil {
+		user = parsedURI.User.Username()
+	}
+
+	options.ContextName = strings.Trim(parsedURI.Path, "/")
+
+	query := parsedURI.Query()
+
+	version := query.Get("version")
+	if version == "" {
+		version = "2c"
+		for _, key := range []string{"security_level", "auth", "auth_protocol", "privacy", "privacy_protocol"} {
+			if query.Get(key) != "" {
+				version = "3"
+				break
+			}
+		}
+	}
+
+	options.Version, err = normaliseVersion(version)
+	if err != nil {
+		return sessionOptions{}, err
+	}
+
+	if options.Version == "3" {
+		options.SecurityUsername = user
+	} else {
+		options.Community = user
+	}
+
+	for key, values := range query {
+		value := values[len(values)-1]
+
+		switch key {
+		case "version":
+		case "community":
+			options.Community = value
+		case "context", "context_name":
+			options.ContextName = value
+		case "security_username":
+			options.SecurityUsername = value
+		case "security_level":
+			options.SecurityLevel = value
+		case "auth", "auth_protocol":
+			options.AuthProtocol = strings.ToUpper(value)
+		case "auth_password":
+			options.AuthPassword = value
+		case "privacy", "privacy_protocol":
+			options.PrivacyProtocol = strings.ToUpper(value)
+		case "privacy_password":
+			options.PrivacyPassword = value
+		case "transport":
+			options.Transport = value
+		case "timeout":
+			options.Timeout, err = strconv.ParseFloat(value, 64)
+		case "retries":
+			options.Retries, err = strconv.Atoi(value)
+		case "max_oids":
+			options.MaxOids, err = strconv.Atoi(value)
+		case "max_repetitions":
+			options.MaxRepetitions, err = strconv.Atoi(value)
+		case "local_address":
+			options.LocalAddress = value
+		case "local_port":
+			options.LocalPort, err = strconv.Atoi(value)
+		case "local_interface":
+			options.LocalInterface = value
+		case "logging":
+			options.Logging, err = strconv.ParseBool(value)
+		case "encoding":
+			options.Encoding = value
+		case "pipeline_depth":
+			options.PipelineDepth, err = strconv.Atoi(value)
+		case "max_message_size":
+			options.MaxMessageSize, err = strconv.Atoi(value)
+		case "emulate_getbulk":
+			options.EmulateGetBulk, err = strconv.ParseBool(value)
+		case "annotate":
+			options.Annotate, err = strconv.ParseBool(value)
+		case "retry_mode":
+			options.RetryPolicy.Mode = value
+		case "retry_delay":
+			options.RetryPolicy.Delay, err = strconv.ParseFloat(value, 64)
+		case "retry_max_delay":
+			options.RetryPolicy.MaxDelay, err = strconv.ParseFloat(value, 64)
+		case "retry_multiplier":
+			options.RetryPolicy.Multiplier, err = strconv.ParseFloat(value, 64)
+		case "retry_jitter":
+			options.RetryPolicy.Jitter, err = strconv.ParseFloat(value, 64)
+		default:
+			return sessionOptions{}, fmt.Errorf("session URI parameter %#v is unknown", key)
+		}
+
+		if err != nil {
+			return sessionOptions{}, fmt.Errorf("session URI parameter %v=%#v is invalid: %v", key, value, err)
+		}
+	}
+
+	if options.Version == "3" && options.SecurityLevel == "" {
+		options.SecurityLevel = "noAuthNoPriv"
+		if options.AuthProtocol != "" {
+			options.SecurityLevel = "authNoPriv"
+			if options.PrivacyProtocol != "" {
+				options.SecurityLevel = "authPriv"
+			}
+		}
+	}
+
+	err = options.validate()
+	if err != nil {
+		return sessionOptions{}, err
+	}
+
+	return options, nil
+}
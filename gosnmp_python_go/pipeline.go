@@ -0,0 +1,240 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+const maxRequestID = 0x7fffffff // request IDs are Integer32 and gosnmp decodes them as an int
+
+type pipelineRequest struct {
+	pduType        gosnmp.PDUType
+	oids           []string
+	nonRepeaters   uint8
+	maxRepetitions uint8
+}
+
+type pipelineResponse struct {
+	result *gosnmp.SnmpPacket
+	err    error
+}
+
+type pipelineEntry struct {
+	index    int
+	packet   *gosnmp.SnmpPacket
+	attempts int
+	sendAt   time.Time // when to (re)send; zero if we're waiting on a response
+	deadline time.Time // when to give up waiting on a response
+	done     bool
+}
+
+// canPipeline returns true if requests can be pipelined; SNMPv3 can't be as the USM machinery is private to gosnmp
+func (w *wrappedSNMP) canPipeline() bool {
+	return w.pipelineDepth > 1 && w.snmp.Version != gosnmp.Version3 && w.snmp.Conn != nil
+}
+
+func (w *wrappedSNMP) getNextRequestID() uint32 {
+	w.requestID++
+	if w.requestID == 0 || w.requestID > maxRequestID {
+		w.requestID = 1
+	}
+
+	return w.requestID
+}
+
+// sequential makes the requests one after the other, for when they can't be pipelined
+func (w *wrappedSNMP) sequential(requests []pipelineRequest) []pipelineResponse {
+	responses := make([]pipelineResponse, len(requests))
+
+	for i, request := range requests {
+		switch request.pduType {
+		case gosnmp.GetRequest:
+			responses[i].result, responses[i].err = w.get(request.oids)
+		case gosnmp.GetNextRequest:
+			responses[i].result, responses[i].err = w.getNext(request.oids)
+		case gosnmp.GetBulkRequest:
+			responses[i].result, responses[i].err = w.getBulk(request.oids, request.nonRepeaters, request.maxRepetitions)
+		default:
+			responses[i].err = fmt.Errorf("unsupported PDU type %v", request.pduType)
+		}
+	}
+
+	return responses
+}
+
+// pipeline makes the requests with up to pipelineDepth of them in flight at once (over the session's socket), matching
+// responses to requests by request ID; retries and timeouts behave as they do for individual requests
+func (w *wrappedSNMP) pipeline(requests []pipelineRequest) []pipelineResponse {
+	if w.snmp.Version == gosnmp.Version1 && hasGetBulkRequest(requests) {
+		return w.pipelineEmulatingGetBulk(requests)
+	}
+
+	if !w.canPipeline() {
+		return w.sequential(requests)
+	}
+
+	responses := make([]pipelineResponse, len(requests))
+
+	complete := func(entry *pipelineEntry, result *gosnmp.SnmpPacket, err error) {
+		entry.done = true
+		responses[entry.index] = pipelineResponse{result, err}
+	}
+
+	target, err := net.ResolveUDPAddr("udp", net.JoinHostPort(w.snmp.Target, strconv.Itoa(int(w.snmp.Port))))
+	if err != nil {
+		for i := range responses {
+			responses[i].err = fmt.Errorf("Error on ResolveUDPAddr: %v", err)
+		}
+
+		return responses
+	}
+
+	retries := w.snmp.Retries
+	if retries < 0 {
+		retries = 0
+	}
+
+	attemptTimeout := w.snmp.Timeout / time.Duration(retries+1)
+
+	entriesByRequestID := make(map[uint32]*pipelineEntry)
+	active := make([]*pipelineEntry, 0)
+	next := 0
+	remaining := len(requests)
+	buf := make([]byte, 65535)
+
+	for remaining > 0 {
+		now := time.Now()
+
+		// top up the window
+		for len(active) < w.pipelineDepth && next < len(requests) {
+			request := requests[next]
+
+			entry := &pipelineEntry{index: next, sendAt: now}
+			next++
+
+			if len(request.oids) > w.snmp.MaxOids {
+				complete(entry, nil, fmt.Errorf("oid count (%d) is greater than MaxOids (%d)", len(request.oids), w.snmp.MaxOids))
+				remaining--
+				continue
+			}
+
+			oids, err := formatOIDs(request.oids)
+			if err != nil {
+				complete(entry, nil, err)
+				remaining--
+				continue
+			}
+
+			pdus := make([]gosnmp.SnmpPDU, 0)
+			for _, oid := range oids {
+				pdus = append(pdus, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Null})
+			}
+
+			entry.packet = &gosnmp.SnmpPacket{
+				Version:        w.snmp.Version,
+				Community:      w.snmp.Community,
+				PDUType:        request.pduType,
+				NonRepeaters:   request.nonRepeaters,
+				MaxRepetitions: request.maxRepetitions,
+				Variables:      pdus,
+			}
+
+			active = append(active, entry)
+		}
+
+		// send anything that's due and give up on anything that's run out of attempts
+		earliest := now.Add(attemptTimeout)
+		for _, entry := range active {
+			if !entry.sendAt.IsZero() && !now.Before(entry.sendAt) {
+				entry.packet.RequestID = w.getNextRequestID()
+				entriesByRequestID[entry.packet.RequestID] = entry
+
+				outBuf, err := entry.packet.MarshalMsg()
+				if err == nil {
+					_, err = w.snmp.Conn.WriteTo(outBuf, target)
+				}
+
+				if err != nil {
+					complete(entry, nil, fmt.Errorf("Error on Conn.WriteTo: %v", err))
+					continue
+				}
+
+				entry.attempts++
+				entry.sendAt = time.Time{}
+				entry.deadline = now.Add(attemptTimeout)
+			} else if entry.sendAt.IsZero() && !now.Before(entry.deadline) {
+				if entry.attempts > retries {
+					complete(entry, nil, fmt.Errorf("Request timeout (after %d retries)", entry.attempts-1))
+					continue
+				}
+
+				entry.sendAt = now.Add(w.retryPolicy.getDelay(entry.attempts))
+			}
+
+			if !entry.sendAt.IsZero() && entry.sendAt.Before(earliest) {
+				earliest = entry.sendAt
+			} else if entry.sendAt.IsZero() && entry.deadline.Before(earliest) {
+				earliest = entry.deadline
+			}
+		}
+
+		stillActive := make([]*pipelineEntry, 0, len(active))
+		for _, entry := range active {
+			if entry.done {
+				remaining--
+				continue
+			}
+
+			stillActive = append(stillActive, entry)
+		}
+		active = stillActive
+
+		if remaining == 0 {
+			break
+		}
+
+		if len(active) == 0 {
+			continue
+		}
+
+		_ = w.snmp.Conn.SetReadDeadline(earliest)
+
+		n, _, err := w.snmp.Conn.ReadFrom(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+
+			for _, entry := range active {
+				complete(entry, nil, fmt.Errorf("Error reading from UDP: %v", err))
+				remaining--
+			}
+
+			break
+		}
+
+		resp := make([]byte, n)
+		copy(resp, buf[:n])
+
+		result := w.snmp.UnmarshalTrap(resp)
+		if result == nil || len(result.Variables) < 1 {
+			continue
+		}
+
+		recoverUnknownValues(result, resp)
+
+		// late responses to earlier attempts are as good as any
+		entry, ok := entriesByRequestID[result.RequestID]
+		if !ok || entry.done {
+			continue
+		}
+
+		complete(entry, result, nil)
+	}
+
+	return responses
+}
@@ -0,0 +1,307 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+const (
+	defaultPollWorkers = 16
+	defaultPollPerHost = 1
+)
+
+type pollJob struct {
+	SessionID      uint64      `json:"session_id"`
+	Operation      string      `json:"operation"` // get, getNext, getBulk, walk, walkBulk or walkMany
+	OIDs           []string    `json:"oids"`
+	NonRepeaters   uint8       `json:"non_repeaters"`   // getBulk only
+	MaxRepetitions uint8       `json:"max_repetitions"` // getBulk only; 0 for the session default
+	Timeout        float64     `json:"timeout"`         // seconds; 0 for the session default
+	Retries        *int        `json:"retries"`         // omit for the session default
+	Rates          bool        `json:"rates"`           // compute the rates of any counters (see computeRates)
+	WalkOptions    walkOptions `json:"walk_options"`    // walk / walkBulk / walkMany only
+}
+
+type pollBatch struct {
+	Workers int       `json:"workers"`  // how many jobs may run at once overall
+	PerHost int       `json:"per_host"` // how many jobs may run at once against the same host
+	Jobs    []pollJob `json:"jobs"`
+}
+
+type pollResult struct {
+	SessionID uint64
+	Results   []multiResult
+	Error     string
+	Latency   float64       // seconds spent on the operation itself (not waiting for a worker or the session)
+	Rates     []counterRate `json:",omitempty"`
+}
+
+func parsePollBatch(batchJSON string) (pollBatch, error) {
+	batch := pollBatch{
+		Workers: defaultPollWorkers,
+		PerHost: defaultPollPerHost,
+	}
+
+	err := json.Unmarshal([]byte(batchJSON), &batch)
+	if err != nil {
+		return pollBatch{}, fmt.Errorf("failed to parse poll batch: %v", err)
+	}
+
+	if batch.Workers < 1 {
+		return pollBatch{}, fmt.Errorf("workers %v is invalid; must be greater than 0", batch.Workers)
+	}
+
+	if batch.PerHost < 1 {
+		return pollBatch{}, fmt.Errorf("per_host %v is invalid; must be greater than 0", batch.PerHost)
+	}
+
+	for i := range batch.Jobs {
+		err = batch.Jobs[i].WalkOptions.validate()
+		if err != nil {
+			return pollBatch{}, err
+		}
+	}
+
+	return batch, nil
+}
+
+func getSession(sessionID uint64) (sessionInterface, bool) {
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	return val, ok
+}
+
+// poll runs a single operation of any kind against each of the oids (or all of them at once for getBulk / walkMany)
+func (s *session) poll(operation string, oids []string, nonRepeaters uint8, maxRepetitions uint8, options walkOptions) ([]multiResult, error) {
+	emptyMultiResults := make([]multiResult, 0)
+
+	if len(oids) == 0 {
+		return emptyMultiResults, fmt.Errorf("oids must be length of 1 or more")
+	}
+
+	multiResults := make([]multiResult, 0)
+
+	switch strings.ToLower(strings.Replace(operation, "_", "", -1)) {
+	case "get":
+		for _, oid := range oids {
+			multiResult, err := s.get(oid)
+			if err != nil {
+				return emptyMultiResults, err
+			}
+
+			multiResults = append(multiResults, multiResult)
+		}
+	case "getnext":
+		for _, oid := range oids {
+			multiResult, err := s.getNext(oid)
+			if err != nil {
+				return emptyMultiResults, err
+			}
+
+			multiResults = append(multiResults, multiResult)
+		}
+	case "getbulk":
+		if maxRepetitions == 0 {
+			maxRepetitions = s.getSNMP().MaxRepetitions
+		}
+
+		var err error
+
+		multiResults, err = s.getBulk(oids, nonRepeaters, maxRepetitions)
+		if err != nil {
+			return emptyMultiResults, err
+		}
+	case "walk":
+		for _, oid := range oids {
+			walkResults, err := s.walk(oid, options)
+			if err != nil {
+				return emptyMultiResults, err
+			}
+
+			multiResults = append(multiResults, walkResults...)
+		}
+	case "walkbulk":
+		for _, oid := range oids {
+			walkResults, err := s.walkBulk(oid, options)
+			if err != nil {
+				return emptyMultiResults, err
+			}
+
+			multiResults = append(multiResults, walkResults...)
+		}
+	case "walkmany":
+		var err error
+
+		multiResults, err = s.walkMany(oids, options)
+		if err != nil {
+			return emptyMultiResults, err
+		}
+	default:
+		return emptyMultiResults, fmt.Errorf("operation %#v is invalid; must be one of get, getNext, getBulk, walk, walkBulk or walkMany", operation)
+	}
+
+	s.annotateMultiResults(multiResults)
+
+	return multiResults, nil
+}
+
+// runPollJob runs a job against its session (holding the session's lock) and returns the result
+func runPollJob(job pollJob, s sessionInterface) (result pollResult) {
+	result = pollResult{
+		SessionID: job.SessionID,
+		Results:   make([]multiResult, 0),
+	}
+
+	// permit recovering from a panic but record the error
+	defer func() {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("poll", job.SessionID, s, handledError)
+				result.Results = make([]multiResult, 0)
+				result.Error = handledError.Error()
+			}
+		}
+	}()
+
+	retries := -1
+	if job.Retries != nil {
+		retries = *job.Retries
+	}
+
+	s.lock()
+	defer s.unlock()
+	defer s.applyCallOverrides(secondsToDuration(job.Timeout), retries)()
+
+	// sysUpTime tells us if the agent has restarted since the last sample (and so whether the counters are comparable)
+	upTime, hasUpTime := 0, false
+	if job.Rates {
+		upTimeResult, err := s.get(sysUpTimeOID)
+		if err == nil && upTimeResult.valueType == gosnmp.TimeTicks {
+			upTime, hasUpTime = upTimeResult.IntValue, true
+		}
+	}
+
+	start := time.Now()
+	multiResults, err := s.poll(job.Operation, job.OIDs, job.NonRepeaters, job.MaxRepetitions, job.WalkOptions)
+	latency := time.Since(start)
+	result.Latency = latency.Seconds()
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Results = multiResults
+
+	if job.Rates {
+		result.Rates = computeRates(job.SessionID, upTime, hasUpTime, multiResults, start.Add(latency/2))
+	}
+
+	return result
+}
+
+// pollJobs runs the jobs with at most workers of them in flight overall and at most perHost of them in flight against the same
+// host (across all sessions for it) and returns a pollResult for each (in the same order)
+func pollJobs(jobs []pollJob, workers int, perHost int) []pollResult {
+	results := make([]pollResult, len(jobs))
+
+	jobSessions := make([]sessionInterface, len(jobs))
+	jobHosts := make([]string, len(jobs))
+
+	pending := make([]int, 0)
+	for i, job := range jobs {
+		s, ok := getSession(job.SessionID)
+		if !ok {
+			results[i] = pollResult{
+				SessionID: job.SessionID,
+				Results:   make([]multiResult, 0),
+				Error:     fmt.Sprintf("sessionID %v does not exist", job.SessionID),
+			}
+			continue
+		}
+
+		jobSessions[i] = s
+		jobHosts[i] = strings.ToLower(s.getSNMP().Target)
+		pending = append(pending, i)
+	}
+
+	var mutex sync.Mutex
+	cond := sync.NewCond(&mutex)
+	inFlightByHost := make(map[string]int)
+
+	// take returns the first pending job whose host has capacity, waiting for one if need be; false if there's nothing left
+	take := func() (int, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for {
+			if len(pending) == 0 {
+				return 0, false
+			}
+
+			for j, i := range pending {
+				if inFlightByHost[jobHosts[i]] < perHost {
+					pending = append(pending[:j], pending[j+1:]...)
+					inFlightByHost[jobHosts[i]]++
+					return i, true
+				}
+			}
+
+			cond.Wait()
+		}
+	}
+
+	release := func(i int) {
+		mutex.Lock()
+		inFlightByHost[jobHosts[i]]--
+		mutex.Unlock()
+
+		cond.Broadcast()
+	}
+
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				i, ok := take()
+				if !ok {
+					cond.Broadcast() // wake anyone else waiting so they can see there's nothing left
+					return
+				}
+
+				results[i] = runPollJob(jobs[i], jobSessions[i])
+
+				release(i)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func pollBatchJSON(batch pollBatch) (string, error) {
+	results := pollJobs(batch.Jobs, batch.Workers, batch.PerHost)
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(resultsBytes), nil
+}
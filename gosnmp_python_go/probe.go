@@ -0,0 +1,248 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	defaultProbeTimeout = 1.0 // seconds; short, as most candidates are expected to fail (and SNMPv1 / SNMPv2c only fail by timing out)
+	defaultProbeRetries = 0
+
+	sysDescrOID    = ".1.3.6.1.2.1.1.1.0"
+	sysObjectIDOID = ".1.3.6.1.2.1.1.2.0"
+)
+
+var defaultProbeOrder = []string{"3", "2c", "1"}
+
+// probeRequest is what RPCProbe receives as JSON; each of the candidates is session options (as per NewRPCSessionFromOptions) less
+// the hostname, with the port, timeout and retries defaulting to those given here
+type probeRequest struct {
+	Candidates []json.RawMessage `json:"candidates"`
+	Order      []string          `json:"order"` // the order the versions are tried in (candidates of the same version in the order given)
+	Port       int               `json:"port"`
+	Timeout    float64           `json:"timeout"` // seconds, fractions permitted (e.g. 0.2)
+	Retries    int               `json:"retries"`
+}
+
+// probeAttempt is a candidate that was tried and why it failed
+type probeAttempt struct {
+	Candidate int    // index into the candidates as given
+	Version   string // "1", "2c" or "3"
+	Error     string
+	Latency   float64 // seconds
+}
+
+type probeResult struct {
+	Success     bool
+	Candidate   int             // index into the candidates as given; -1 if none worked
+	Options     json.RawMessage // the candidate that worked (as given); null if none did
+	Version     string
+	SysObjectID string
+	SysDescr    string
+	Latency     float64        // seconds
+	Failures    []probeAttempt // the candidates tried before the one that worked (all of them if none did)
+}
+
+type probeCandidate struct {
+	index   int
+	raw     json.RawMessage
+	options sessionOptions
+	err     error // the candidate's options are no good
+}
+
+func parseProbeRequest(target string, requestJSON string) ([]probeCandidate, error) {
+	request := probeRequest{
+		Order:   defaultProbeOrder,
+		Port:    defaultPort,
+		Timeout: defaultProbeTimeout,
+		Retries: defaultProbeRetries,
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(requestJSON)))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse probe candidates: %v", err)
+	}
+
+	if len(request.Candidates) == 0 {
+		return nil, fmt.Errorf("candidates must be length of 1 or more")
+	}
+
+	ranks := make(map[string]int)
+	for i, version := range request.Order {
+		normalisedVersion, err := normaliseVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("order %v: %v", i, err)
+		}
+
+		if _, ok := ranks[normalisedVersion]; !ok {
+			ranks[normalisedVersion] = i
+		}
+	}
+
+	candidates := make([]probeCandidate, 0)
+	for i, raw := range request.Candidates {
+		options := getDefaultSessionOptions()
+		options.Port = request.Port
+		options.Timeout = request.Timeout
+		options.Retries = request.Retries
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(&options)
+		if err != nil {
+			err = fmt.Errorf("failed to parse session options: %v", err)
+		} else if options.Hostname != "" {
+			err = fmt.Errorf("hostname %#v is invalid; the target is what's probed, so candidates mustn't have one", options.Hostname)
+		} else {
+			options.Hostname = target
+			err = options.validate()
+		}
+
+		// versions left out of the order aren't tried
+		if err == nil {
+			if _, ok := ranks[options.Version]; !ok {
+				continue
+			}
+		}
+
+		candidates = append(candidates, probeCandidate{index: i, raw: raw, options: options, err: err})
+	}
+
+	// invalid candidates go first (they cost nothing and the caller will want to know)
+	rank := func(candidate probeCandidate) int {
+		if candidate.err != nil {
+			return -1
+		}
+
+		return ranks[candidate.options.Version]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return rank(candidates[i]) < rank(candidates[j])
+	})
+
+	return candidates, nil
+}
+
+// probeOne reads sysObjectID and sysDescr with a session built from the options; any response that isn't an error (or an SNMPv3
+// report) shows the credentials work, even if the agent doesn't have them (they're left empty); the session is its own (never stored
+// or shared) so there's nothing to lock
+func probeOne(options sessionOptions) (sysObjectID string, sysDescr string, err error) {
+	s := newSession(options)
+	defer func() {
+		_ = s.close()
+	}()
+
+	err = s.connect()
+	if err != nil {
+		return "", "", err
+	}
+
+	result, err := s.snmp.get([]string{sysObjectIDOID, sysDescrOID})
+	if err != nil {
+		return "", "", err
+	}
+
+	if isNoSuchNameError(result) {
+		return "", "", nil
+	}
+
+	err = checkForErrors(result)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(result.Variables) == 0 {
+		return "", "", fmt.Errorf("nothing returned for Get oids=%v", []string{sysObjectIDOID, sysDescrOID})
+	}
+
+	err = checkForSNMPv3Issues(sysObjectIDOID, result)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, variable := range result.Variables {
+		switch variable.Name {
+		case sysObjectIDOID:
+			sysObjectID, _ = variable.Value.(string)
+		case sysDescrOID:
+			value, _ := variable.Value.([]byte)
+			sysDescr = string(value)
+		}
+	}
+
+	return sysObjectID, sysDescr, nil
+}
+
+// probe tries each of the candidates against the target (in the requested order) until one of them can read sysObjectID and
+// sysDescr
+func probe(target string, requestJSON string) (probeResult, error) {
+	candidates, err := parseProbeRequest(target, requestJSON)
+	if err != nil {
+		return probeResult{}, err
+	}
+
+	result := probeResult{
+		Candidate: -1,
+		Options:   json.RawMessage("null"),
+		Failures:  make([]probeAttempt, 0),
+	}
+
+	for _, candidate := range candidates {
+		if candidate.err != nil {
+			result.Failures = append(result.Failures, probeAttempt{Candidate: candidate.index, Error: candidate.err.Error()})
+			continue
+		}
+
+		start := time.Now()
+		sysObjectID, sysDescr, err := probeOne(candidate.options)
+		latency := time.Since(start).Seconds()
+
+		if err != nil {
+			result.Failures = append(
+				result.Failures,
+				probeAttempt{
+					Candidate: candidate.index,
+					Version:   candidate.options.Version,
+					Error:     err.Error(),
+					Latency:   latency,
+				},
+			)
+			continue
+		}
+
+		result.Success = true
+		result.Candidate = candidate.index
+		result.Options = candidate.raw
+		result.Version = candidate.options.Version
+		result.SysObjectID = sysObjectID
+		result.SysDescr = sysDescr
+		result.Latency = latency
+
+		break
+	}
+
+	return result, nil
+}
+
+func probeJSON(target string, requestJSON string) (string, error) {
+	result, err := probe(target, requestJSON)
+	if err != nil {
+		return "{}", err
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return "{}", err
+	}
+
+	return string(resultBytes), nil
+}
@@ -0,0 +1,78 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	a := newTestAgent(t, append(testIfTable(1), testVarbind{oid: sysObjectIDOID, tag: 0x06, data: berOID(".1.3.6.1.4.1.99999")}))
+	defer a.close()
+
+	a.setCommunity("right")
+
+	requestJSON := fmt.Sprintf(
+		`{"port": %d, "timeout": 0.2, "candidates": [{"version": "2c", "community": "wrong"}, {"version": "2c", "community": "right"}]}`,
+		a.port(),
+	)
+
+	resultJSON, err := RPCProbe("127.0.0.1", requestJSON)
+	if err != nil {
+		t.Fatalf("failed to probe: %v", err)
+	}
+
+	result := probeResult{}
+	err = json.Unmarshal([]byte(resultJSON), &result)
+	if err != nil {
+		t.Fatalf("failed to parse %v: %v", resultJSON, err)
+	}
+
+	if !result.Success || result.Candidate != 1 || result.Version != "2c" {
+		t.Errorf("expected candidate 1 (SNMPv2c) to work, got %v", resultJSON)
+	}
+
+	if result.SysObjectID != ".1.3.6.1.4.1.99999" || result.SysDescr != "test agent" {
+		t.Errorf("expected the agent's sysObjectID and sysDescr, got %#v and %#v", result.SysObjectID, result.SysDescr)
+	}
+
+	if len(result.Failures) != 1 || result.Failures[0].Candidate != 0 || result.Failures[0].Error == "" {
+		t.Errorf("expected candidate 0 to fail, got %v", result.Failures)
+	}
+}
+
+func TestParseProbeRequest(t *testing.T) {
+	candidates, err := parseProbeRequest(
+		"10.0.0.1",
+		`{"order": ["1", "2c"], "candidates": [{"version": "2c"}, {"version": "1", "hostname": "10.0.0.2"}, {"version": "1"}]}`,
+	)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	// the invalid candidate first, then by version in the order given
+	expected := []int{1, 2, 0}
+	if len(candidates) != len(expected) {
+		t.Fatalf("expected candidates %v, got %v", expected, candidates)
+	}
+
+	for i, candidate := range candidates {
+		if candidate.index != expected[i] {
+			t.Errorf("expected candidates %v, got %v", expected, candidates)
+			break
+		}
+
+		if candidate.err == nil && candidate.options.Hostname != "10.0.0.1" {
+			t.Errorf("candidate %v: expected the target to be probed, got %v", candidate.index, candidate.options.Hostname)
+		}
+	}
+
+	if candidates[0].err == nil || candidates[0].err.Error() != `hostname "10.0.0.2" is invalid; the target is what's probed, so candidates mustn't have one` {
+		t.Errorf("expected the candidate with a hostname to be refused, got %v", candidates[0].err)
+	}
+
+	_, err = parseProbeRequest("10.0.0.1", `{"candidates": []}`)
+	if err == nil || err.Error() != "candidates must be length of 1 or more" {
+		t.Errorf("expected no candidates to be refused, got %v", err)
+	}
+}
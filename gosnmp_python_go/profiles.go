@@ -0,0 +1,166 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+const profileSmoothing = 0.2 // how much weight each new sample gets in a targetProfile's moving averages
+
+// targetProfile is what's been learned about a device across all of the sessions to it (with the same SNMP version, as SNMPv3
+// responses carry more overhead), so that new sessions needn't start over
+type targetProfile struct {
+	Target           string  // host:port
+	Version          string  // 1, 2c or 3
+	MaxRepetitions   uint8   // the optimalMaxRepetitions learned by walkBulk; 0 if not learned yet
+	ResponseVarbinds float64 // moving average of the varbinds in each GetBulk response
+	ResponseBytes    float64 // moving average of the size (in bytes) of each GetBulk response
+	Latency          float64 // moving average of the seconds taken by each GetBulk (including any retries)
+	Samples          int     // GetBulk responses seen
+	UpdatedAt        float64 // unix time (in seconds)
+}
+
+var profileMutex sync.Mutex
+var targetProfiles = make(map[profileKey]*targetProfile)
+
+type profileKey struct {
+	target  string // host:port
+	version string
+}
+
+func getProfileKey(target string, port uint16, version gosnmp.SnmpVersion) profileKey {
+	return profileKey{
+		target:  net.JoinHostPort(strings.ToLower(target), strconv.Itoa(int(port))),
+		version: version.String(),
+	}
+}
+
+// getTargetProfile returns a copy of the profile for the target, if there is one
+func getTargetProfile(key profileKey) (targetProfile, bool) {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	profile, ok := targetProfiles[key]
+	if !ok {
+		return targetProfile{}, false
+	}
+
+	return *profile, true
+}
+
+// getOrCreateTargetProfile must be called with profileMutex held
+func getOrCreateTargetProfile(key profileKey) *targetProfile {
+	profile, ok := targetProfiles[key]
+	if !ok {
+		profile = &targetProfile{
+			Target:  key.target,
+			Version: key.version,
+		}
+		targetProfiles[key] = profile
+	}
+
+	return profile
+}
+
+func smooth(average float64, sample float64, samples int) float64 {
+	if samples == 0 {
+		return sample
+	}
+
+	return average + profileSmoothing*(sample-average)
+}
+
+// recordGetBulkResponse folds a GetBulk response into the target's profile
+func recordGetBulkResponse(key profileKey, result *gosnmp.SnmpPacket, responseSize int, latency time.Duration) {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	profile := getOrCreateTargetProfile(key)
+
+	profile.ResponseVarbinds = smooth(profile.ResponseVarbinds, float64(len(result.Variables)), profile.Samples)
+	profile.ResponseBytes = smooth(profile.ResponseBytes, float64(responseSize), profile.Samples)
+	profile.Latency = smooth(profile.Latency, latency.Seconds(), profile.Samples)
+	profile.Samples++
+	profile.UpdatedAt = toUnixSeconds(time.Now())
+}
+
+// recordMaxRepetitions records the optimalMaxRepetitions walkBulk has settled on for the target
+func recordMaxRepetitions(key profileKey, maxRepetitions uint8) {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	profile := getOrCreateTargetProfile(key)
+
+	profile.MaxRepetitions = maxRepetitions
+	profile.UpdatedAt = toUnixSeconds(time.Now())
+}
+
+// exportTargetProfiles returns all of the profiles (ordered by target, then version)
+func exportTargetProfiles() []targetProfile {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	profiles := make([]targetProfile, 0)
+	for _, profile := range targetProfiles {
+		profiles = append(profiles, *profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Target != profiles[j].Target {
+			return profiles[i].Target < profiles[j].Target
+		}
+
+		return profiles[i].Version < profiles[j].Version
+	})
+
+	return profiles
+}
+
+// importTargetProfiles adds the profiles (as given by exportTargetProfiles), replacing any already held for the same targets (and
+// versions)
+func importTargetProfiles(profilesJSON string) (int, error) {
+	profiles := make([]targetProfile, 0)
+
+	err := json.Unmarshal([]byte(profilesJSON), &profiles)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse profiles: %v", err)
+	}
+
+	for i, profile := range profiles {
+		host, port, err := net.SplitHostPort(profile.Target)
+		if err != nil {
+			return 0, fmt.Errorf("profile %v has an invalid target %#v; must be host:port", i, profile.Target)
+		}
+
+		portNumber, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("profile %v has an invalid target %#v; must be host:port", i, profile.Target)
+		}
+
+		version, err := normaliseVersion(profile.Version)
+		if err != nil {
+			return 0, fmt.Errorf("profile %v has an invalid version: %v", i, err)
+		}
+
+		key := getProfileKey(host, uint16(portNumber), getVersion(version))
+		profiles[i].Target, profiles[i].Version = key.target, key.version
+	}
+
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	for i := range profiles {
+		profile := profiles[i]
+		targetProfiles[profileKey{target: profile.Target, version: profile.Version}] = &profile
+	}
+
+	return len(profiles), nil
+}
@@ -0,0 +1,58 @@
+package gosnmp_python_go
+
+import (
+	"testing"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+func TestConnectSeedsMaxRepetitionsFromProfile(t *testing.T) {
+	const port = 16161
+
+	defer func() {
+		profileMutex.Lock()
+		delete(targetProfiles, getProfileKey("127.0.0.1", port, gosnmp.Version2c))
+		profileMutex.Unlock()
+	}()
+
+	tests := []struct {
+		name           string
+		version        string
+		learned        uint8
+		maxRepetitions int
+		expect         uint8
+	}{
+		{"seeded", "2c", 10, 0, 10},
+		{"capped at the default", "2c", 50, 0, defaultMaxRepetitions},
+		{"given explicitly", "2c", 10, 5, 5},
+		{"other version", "1", 10, 0, defaultMaxRepetitions},
+	}
+
+	for _, test := range tests {
+		recordMaxRepetitions(getProfileKey("127.0.0.1", port, gosnmp.Version2c), test.learned)
+
+		options := getDefaultSessionOptions()
+		options.Hostname = "127.0.0.1"
+		options.Port = port
+		options.Version = test.version
+		options.MaxRepetitions = test.maxRepetitions
+
+		s, err := newSessionFromOptions(options)
+		if err != nil {
+			t.Fatalf("%v: failed to create session: %v", test.name, err)
+		}
+
+		w := s.snmp.(*wrappedSNMP)
+
+		err = w.connect()
+		if err != nil {
+			t.Fatalf("%v: failed to connect: %v", test.name, err)
+		}
+
+		if w.optimalMaxRepetitions != test.expect {
+			t.Errorf("%v: expected to start at %v, got %v", test.name, test.expect, w.optimalMaxRepetitions)
+		}
+
+		_ = w.close()
+	}
+}
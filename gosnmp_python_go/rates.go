@@ -0,0 +1,182 @@
+package gosnmp_python_go
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+const (
+	sysUpTimeOID                  = ".1.3.6.1.2.1.1.3.0"
+	ifEntryOID                    = ".1.3.6.1.2.1.2.2.1"
+	ifXEntryOID                   = ".1.3.6.1.2.1.31.1.1.1"
+	ifCounterDiscontinuityTimeOID = ".1.3.6.1.2.1.31.1.1.1.19"
+
+	// a Counter64 that has wrapped faster than this (per second; about 8Tbit/s of octets) has really been reset- a Counter64 only
+	// wraps after years at any real rate, so a small value after a large one is far more likely a reset that nothing told us of
+	maxWrappedCounter64Rate = 1e12
+)
+
+// counterRate is the per-second rate of a Counter32 / Counter64 since the last time it was polled (for the same session)
+type counterRate struct {
+	OID      string
+	Value    uint64  // the raw counter value
+	Delta    uint64  // how much the counter has gone up by (allowing for a wrap)
+	Interval float64 // seconds since the last sample
+	Rate     float64 // Delta / Interval
+	Wrapped  bool
+	Valid    bool   // false if there's no rate to give (see Reason)
+	Reason   string // why the rate isn't valid (e.g. the first sample or a discontinuity)
+}
+
+type counterSample struct {
+	valueType gosnmp.Asn1BER
+	value     uint64
+	at        time.Time
+}
+
+type sessionRateState struct {
+	upTime             int
+	hasUpTime          bool
+	discontinuityTimes map[string]int // by ifIndex
+	samples            map[string]counterSample
+}
+
+var rateMutex sync.Mutex
+var rateStates = make(map[uint64]*sessionRateState)
+
+func isCounter(valueType gosnmp.Asn1BER) bool {
+	return valueType == gosnmp.Counter32 || valueType == gosnmp.Counter64
+}
+
+// getCounterValue recovers the raw value of a Counter32 / Counter64 (a Counter64 may have overflowed IntValue)
+func getCounterValue(result multiResult) uint64 {
+	if result.valueType == gosnmp.Counter32 {
+		return uint64(uint32(result.IntValue))
+	}
+
+	return uint64(result.IntValue)
+}
+
+// getIfIndex returns the ifIndex for an ifEntry / ifXEntry OID (e.g. "3" for ifInOctets.3); empty for anything else
+func getIfIndex(oid string) string {
+	for _, prefix := range []string{ifEntryOID, ifXEntryOID} {
+		if !strings.HasPrefix(oid, prefix+".") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(oid, prefix+"."), ".", 2)
+		if len(parts) == 2 {
+			return parts[1]
+		}
+	}
+
+	return ""
+}
+
+// computeRates records the counters in the results (sampled at the given time) and returns the rate of each since it was last
+// recorded for the session; upTime (sysUpTime, if hasUpTime) going backwards means the agent restarted and an ifIndex's
+// ifCounterDiscontinuityTime (if present in the results) changing means that interface's counters were reset
+func computeRates(sessionID uint64, upTime int, hasUpTime bool, results []multiResult, at time.Time) []counterRate {
+	rateMutex.Lock()
+	defer rateMutex.Unlock()
+
+	state, ok := rateStates[sessionID]
+	if !ok {
+		state = &sessionRateState{
+			discontinuityTimes: make(map[string]int),
+			samples:            make(map[string]counterSample),
+		}
+		rateStates[sessionID] = state
+	}
+
+	restarted := false
+	if hasUpTime {
+		restarted = state.hasUpTime && upTime < state.upTime
+		state.upTime = upTime
+		state.hasUpTime = true
+	}
+
+	discontinuities := make(map[string]bool)
+	for _, result := range results {
+		if !strings.HasPrefix(result.OID, ifCounterDiscontinuityTimeOID+".") || result.Type != "int" {
+			continue
+		}
+
+		ifIndex := strings.TrimPrefix(result.OID, ifCounterDiscontinuityTimeOID+".")
+
+		lastDiscontinuityTime, ok := state.discontinuityTimes[ifIndex]
+		if ok && lastDiscontinuityTime != result.IntValue {
+			discontinuities[ifIndex] = true
+		}
+
+		state.discontinuityTimes[ifIndex] = result.IntValue
+	}
+
+	rates := make([]counterRate, 0)
+	for _, result := range results {
+		if !isCounter(result.valueType) {
+			continue
+		}
+
+		rate := counterRate{
+			OID:   result.OID,
+			Value: getCounterValue(result),
+		}
+
+		last, ok := state.samples[result.OID]
+		interval := at.Sub(last.at).Seconds()
+
+		switch {
+		case !ok:
+			rate.Reason = "no previous sample"
+		case restarted:
+			rate.Reason = "sysUpTime went backwards (agent restarted)"
+		case discontinuities[getIfIndex(result.OID)]:
+			rate.Reason = "ifCounterDiscontinuityTime changed"
+		case last.valueType != result.valueType:
+			rate.Reason = "type changed"
+		case interval <= 0:
+			rate.Reason = "no time elapsed"
+		default:
+			// modulo 2^32 / 2^64, so a wrap comes out right
+			if result.valueType == gosnmp.Counter32 {
+				rate.Delta = uint64(uint32(rate.Value) - uint32(last.value))
+			} else {
+				rate.Delta = rate.Value - last.value
+			}
+
+			wrapped := rate.Value < last.value
+
+			if wrapped && result.valueType == gosnmp.Counter64 && float64(rate.Delta)/interval > maxWrappedCounter64Rate {
+				rate.Delta = 0
+				rate.Reason = "counter went backwards (reset)"
+				break
+			}
+
+			rate.Interval = interval
+			rate.Rate = float64(rate.Delta) / interval
+			rate.Wrapped = wrapped
+			rate.Valid = true
+		}
+
+		state.samples[result.OID] = counterSample{
+			valueType: result.valueType,
+			value:     rate.Value,
+			at:        at,
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates
+}
+
+// forgetRates throws away the samples recorded for the session
+func forgetRates(sessionID uint64) {
+	rateMutex.Lock()
+	delete(rateStates, sessionID)
+	rateMutex.Unlock()
+}
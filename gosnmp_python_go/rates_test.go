@@ -0,0 +1,58 @@
+package gosnmp_python_go
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+func testCounterResult(valueType gosnmp.Asn1BER, value uint64) multiResult {
+	return multiResult{OID: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: "int", IntValue: int(value), valueType: valueType}
+}
+
+func TestComputeRates(t *testing.T) {
+	const sessionID = math.MaxUint64
+	defer forgetRates(sessionID)
+
+	at := time.Unix(1000, 0)
+
+	steps := []struct {
+		name          string
+		valueType     gosnmp.Asn1BER
+		upTime        int
+		value         uint64
+		expectValid   bool
+		expectWrapped bool
+		expectDelta   uint64
+		expectReason  string
+	}{
+		{"first sample", gosnmp.Counter32, 100, 1000, false, false, 0, "no previous sample"},
+		{"Counter32 increase", gosnmp.Counter32, 200, 2000, true, false, 1000, ""},
+		{"Counter32 wrap", gosnmp.Counter32, 300, 500, true, true, math.MaxUint32 - 2000 + 501, ""},
+		{"type change", gosnmp.Counter64, 400, math.MaxUint64 - 999, false, false, 0, "type changed"},
+		{"Counter64 wrap", gosnmp.Counter64, 500, 1000, true, true, 2000, ""},
+		{"Counter64 increase", gosnmp.Counter64, 600, 1 << 62, true, false, 1<<62 - 1000, ""},
+		{"Counter64 reset", gosnmp.Counter64, 700, 50, false, false, 0, "counter went backwards (reset)"},
+		{"agent restart", gosnmp.Counter64, 10, 60, false, false, 0, "sysUpTime went backwards (agent restarted)"},
+	}
+
+	for i, step := range steps {
+		interval := 10 * time.Second
+		rates := computeRates(sessionID, step.upTime, true, []multiResult{testCounterResult(step.valueType, step.value)}, at.Add(time.Duration(i)*interval))
+
+		if len(rates) != 1 {
+			t.Fatalf("%v: expected 1 rate, got %+v", step.name, rates)
+		}
+
+		rate := rates[0]
+		if rate.Valid != step.expectValid || rate.Wrapped != step.expectWrapped || rate.Delta != step.expectDelta || rate.Reason != step.expectReason {
+			t.Errorf("%v: unexpected rate %+v", step.name, rate)
+		}
+
+		if rate.Valid && rate.Rate != float64(step.expectDelta)/interval.Seconds() {
+			t.Errorf("%v: expected a rate of %v, got %v", step.name, float64(step.expectDelta)/interval.Seconds(), rate.Rate)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	retryModeFixed       = "fixed"
+	retryModeExponential = "exponential"
+
+	defaultRetryMultiplier = 2.0
+)
+
+var jitterMutex sync.Mutex
+var jitterRandom = rand.New(rand.NewSource(time.Now().UnixNano())) // also used to seed request IDs
+
+// retryPolicy describes how long to wait between retries; the zero value retries immediately (as gosnmp always has)
+type retryPolicy struct {
+	Mode       string  `json:"mode"`       // fixed or exponential
+	Delay      float64 `json:"delay"`      // seconds to wait before the first retry
+	MaxDelay   float64 `json:"max_delay"`  // seconds to cap the wait at (exponential only; 0 for no cap)
+	Multiplier float64 `json:"multiplier"` // how much the wait grows by for each retry (exponential only)
+	Jitter     float64 `json:"jitter"`     // fraction (0 to 1) of the wait to randomly add or remove
+}
+
+func (p *retryPolicy) validate() error {
+	p.Mode = strings.ToLower(p.Mode)
+	switch p.Mode {
+	case "":
+		p.Mode = retryModeFixed
+	case retryModeFixed, retryModeExponential:
+	default:
+		return fmt.Errorf("retry_policy mode %#v is invalid; must be one of fixed or exponential", p.Mode)
+	}
+
+	if p.Delay < 0 {
+		return fmt.Errorf("retry_policy delay %v is invalid; must not be negative", p.Delay)
+	}
+
+	if p.MaxDelay < 0 {
+		return fmt.Errorf("retry_policy max_delay %v is invalid; must not be negative", p.MaxDelay)
+	}
+
+	if p.Multiplier == 0 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+
+	if p.Multiplier < 1 {
+		return fmt.Errorf("retry_policy multiplier %v is invalid; must be at least 1", p.Multiplier)
+	}
+
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry_policy jitter %v is invalid; must be between 0 and 1", p.Jitter)
+	}
+
+	return nil
+}
+
+// isImmediate returns true if there's never any waiting between retries
+func (p *retryPolicy) isImmediate() bool {
+	return p.Delay <= 0
+}
+
+// getDelay returns how long to wait before the given retry (1 for the first retry, 2 for the second etc)
+func (p *retryPolicy) getDelay(retry int) time.Duration {
+	if p.isImmediate() || retry < 1 {
+		return 0
+	}
+
+	delay := p.Delay
+
+	if p.Mode == retryModeExponential {
+		delay = p.Delay * math.Pow(p.Multiplier, float64(retry-1))
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+
+	if p.Jitter > 0 {
+		jitterMutex.Lock()
+		delay = delay * (1 + p.Jitter*(2*jitterRandom.Float64()-1))
+		jitterMutex.Unlock()
+	}
+
+	return secondsToDuration(delay)
+}
@@ -33,8 +33,19 @@ func handlePanic(extra string, sessionID uint64, s sessionInterface, err error)
 	)
 }
 
+// storeSession records the given Session and returns the sessionID it can be referred to by
+func storeSession(s sessionInterface) uint64 {
+	sessionMutex.Lock()
+	sessionID := lastSessionID
+	lastSessionID++
+	sessions[sessionID] = s
+	sessionMutex.Unlock()
+
+	return sessionID
+}
+
 // NewRPCSessionV1 creates a new Session for SNMPv1 and returns the sessionID
-func NewRPCSessionV1(hostname string, port int, community string, timeout, retries int) uint64 {
+func NewRPCSessionV1(hostname string, port int, community string, timeout float64, retries int, localAddress string, localPort int, localInterface string) uint64 {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -44,19 +55,16 @@ func NewRPCSessionV1(hostname string, port int, community string, timeout, retri
 		community,
 		timeout,
 		retries,
+		localAddress,
+		localPort,
+		localInterface,
 	)
 
-	sessionMutex.Lock()
-	sessionID := lastSessionID
-	lastSessionID++
-	sessions[sessionID] = &session
-	sessionMutex.Unlock()
-
-	return sessionID
+	return storeSession(session)
 }
 
 // NewRPCSessionV2c creates a new Session for SNMPv2c and returns the sessionID
-func NewRPCSessionV2c(hostname string, port int, community string, timeout, retries int) uint64 {
+func NewRPCSessionV2c(hostname string, port int, community string, timeout float64, retries int, localAddress string, localPort int, localInterface string) uint64 {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -66,19 +74,16 @@ func NewRPCSessionV2c(hostname string, port int, community string, timeout, retr
 		community,
 		timeout,
 		retries,
+		localAddress,
+		localPort,
+		localInterface,
 	)
 
-	sessionMutex.Lock()
-	sessionID := lastSessionID
-	lastSessionID++
-	sessions[sessionID] = &session
-	sessionMutex.Unlock()
-
-	return sessionID
+	return storeSession(session)
 }
 
 // NewRPCSessionV3 creates a new Session for SNMPv3 and returns the sessionID
-func NewRPCSessionV3(hostname string, port int, contextName, securityUsername, privacyPassword, authPassword, securityLevel, authProtocol, privacyProtocol string, timeout, retries int) uint64 {
+func NewRPCSessionV3(hostname string, port int, contextName, securityUsername, privacyPassword, authPassword, securityLevel, authProtocol, privacyProtocol string, timeout float64, retries int, localAddress string, localPort int, localInterface string) uint64 {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -94,15 +99,48 @@ func NewRPCSessionV3(hostname string, port int, contextName, securityUsername, p
 		privacyProtocol,
 		timeout,
 		retries,
+		localAddress,
+		localPort,
+		localInterface,
 	)
 
-	sessionMutex.Lock()
-	sessionID := lastSessionID
-	lastSessionID++
-	sessions[sessionID] = &session
-	sessionMutex.Unlock()
+	return storeSession(session)
+}
 
-	return sessionID
+// NewRPCSessionFromOptions creates a new Session from a JSON options document and returns the sessionID
+func NewRPCSessionFromOptions(optionsJSON string) (uint64, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	options, err := parseSessionOptions(optionsJSON)
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := newSessionFromOptions(options)
+	if err != nil {
+		return 0, err
+	}
+
+	return storeSession(session), nil
+}
+
+// NewRPCSessionFromURI creates a new Session from an snmp:// URI and returns the sessionID
+func NewRPCSessionFromURI(uri string) (uint64, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	options, err := parseSessionURI(uri)
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := newSessionFromOptions(options)
+	if err != nil {
+		return 0, err
+	}
+
+	return storeSession(session), nil
 }
 
 // RPCConnect calls .connect on the Session identified by the sessionID
@@ -127,6 +165,8 @@ func RPCConnect(sessionID uint64) error {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
 		err = val.connect()
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -136,7 +176,10 @@ func RPCConnect(sessionID uint64) error {
 }
 
 // RPCGet calls .get on the Session identified by the sessionID
-func RPCGet(sessionID uint64, oid string) (string, error) {
+//
+// All of the RPCs that make requests take timeout (seconds, fractions permitted) and retries overrides for that call alone;
+// pass a timeout <= 0 and retries < 0 to use the values the Session was created with.
+func RPCGet(sessionID uint64, oid string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -158,6 +201,9 @@ func RPCGet(sessionID uint64, oid string) (string, error) {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.getJSON(oid)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -167,7 +213,7 @@ func RPCGet(sessionID uint64, oid string) (string, error) {
 }
 
 // RPCGetNext calls .getNext on the Session identified by the sessionID
-func RPCGetNext(sessionID uint64, oid string) (string, error) {
+func RPCGetNext(sessionID uint64, oid string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -189,6 +235,9 @@ func RPCGetNext(sessionID uint64, oid string) (string, error) {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.getNextJSON(oid)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -198,14 +247,14 @@ func RPCGetNext(sessionID uint64, oid string) (string, error) {
 }
 
 // RPCGetBulk calls .getBulk on the Session identified by the sessionID
-func RPCGetBulk(sessionID uint64, oids string, nonRepeaters uint8, maxRepetitions uint8) (string, error) {
+func RPCGetBulk(sessionID uint64, oids string, nonRepeaters uint8, maxRepetitions uint8, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
 	var err error
 	var result string
 
-	// TODO: fix hack wherein gopy doesn't like receiving lists fron Python
+	// oids arrives as JSON (from before gopy could receive lists); RPCGetBulkOIDs takes a list
 	realOids := make([]string, 0)
 	err = json.Unmarshal([]byte(oids), &realOids)
 	if err != nil {
@@ -227,6 +276,9 @@ func RPCGetBulk(sessionID uint64, oids string, nonRepeaters uint8, maxRepetition
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.getBulkJSON(realOids, nonRepeaters, maxRepetitions)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -235,8 +287,76 @@ func RPCGetBulk(sessionID uint64, oids string, nonRepeaters uint8, maxRepetition
 	return result, err
 }
 
+// RPCGetBulkOIDs is RPCGetBulk taking the oids as a list rather than JSON
+func RPCGetBulkOIDs(sessionID uint64, oids []string, nonRepeaters uint8, maxRepetitions uint8, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("getBulkJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.getBulkJSON(oids, nonRepeaters, maxRepetitions)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCGetBulkGrouped calls .getBulkGrouped on the Session identified by the sessionID; the results are grouped by requested OID
+func RPCGetBulkGrouped(sessionID uint64, oids []string, nonRepeaters uint8, maxRepetitions uint8, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("getBulkGroupedJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.getBulkGroupedJSON(oids, nonRepeaters, maxRepetitions)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
 // RPCWalk calls .walk on the Session identified by the sessionID
-func RPCWalk(sessionID uint64, oid string) (string, error) {
+func RPCWalk(sessionID uint64, oid string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -258,7 +378,10 @@ func RPCWalk(sessionID uint64, oid string) (string, error) {
 	}(val)
 
 	if ok {
-		result, err = val.walkJSON(oid)
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.walkJSON(oid, walkOptions{})
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
 	}
@@ -267,7 +390,7 @@ func RPCWalk(sessionID uint64, oid string) (string, error) {
 }
 
 // RPCWalkBulk calls .walkBulk on the Session identified by the sessionID
-func RPCWalkBulk(sessionID uint64, oid string) (string, error) {
+func RPCWalkBulk(sessionID uint64, oid string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -289,7 +412,90 @@ func RPCWalkBulk(sessionID uint64, oid string) (string, error) {
 	}(val)
 
 	if ok {
-		result, err = val.walkBulkJSON(oid)
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.walkBulkJSON(oid, walkOptions{})
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCWalkWithOptions is RPCWalk with walk options (as JSON), e.g.
+// {"stop_oid": ".1.3.6.1.2.1.2.2.1.10", "max_varbinds": 1000, "exclude": [".1.3.6.1.2.1.2.2.1.9"]}
+func RPCWalkWithOptions(sessionID uint64, oid string, options string, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	realOptions, err := parseWalkOptions(options)
+	if err != nil {
+		return "[]", err
+	}
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("walkJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.walkJSON(oid, realOptions)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCWalkBulkWithOptions is RPCWalkBulk with walk options (as JSON), e.g.
+// {"stop_oid": ".1.3.6.1.2.1.2.2.1.10", "max_varbinds": 1000, "exclude": [".1.3.6.1.2.1.2.2.1.9"]}
+func RPCWalkBulkWithOptions(sessionID uint64, oid string, options string, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	realOptions, err := parseWalkOptions(options)
+	if err != nil {
+		return "[]", err
+	}
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("walkBulkJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.walkBulkJSON(oid, realOptions)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
 	}
@@ -297,8 +503,315 @@ func RPCWalkBulk(sessionID uint64, oid string) (string, error) {
 	return result, err
 }
 
+// RPCWalkMany calls .walkMany on the Session identified by the sessionID; all of the oids are walked at once (with a varbind for
+// each in every GetBulk) and options (JSON, may be empty) is as per RPCWalkWithOptions and applies to each of them
+func RPCWalkMany(sessionID uint64, oids []string, options string, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	realOptions := walkOptions{}
+	if options != "" {
+		realOptions, err = parseWalkOptions(options)
+		if err != nil {
+			return "[]", err
+		}
+	}
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("walkManyJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.walkManyJSON(oids, realOptions)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCSetMany calls .setMany on the Session identified by the sessionID; oids, valueTypes and values are parallel lists (each
+// value given as a string and parsed as per its type- see buildSetPDU) and are all set in a single request
+func RPCSetMany(sessionID uint64, oids []string, valueTypes []string, values []string, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("setManyJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.setManyJSON(oids, valueTypes, values)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCBatch calls .batch on the Session identified by the sessionID; operations is a JSON list of objects like
+// {"operation": "getBulk", "oids": [".1.3.6.1.2.1.2.2.1.2"], "non_repeaters": 0, "max_repetitions": 10} and the result is a
+// JSON list of {"Results": [...], "Error": "..."} (one per operation, in the same order)
+func RPCBatch(sessionID uint64, operations string, timeout float64, retries int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	var err error
+	var result string
+
+	realOperations := make([]batchOperation, 0)
+	err = json.Unmarshal([]byte(operations), &realOperations)
+	if err != nil {
+		return "[]", err
+	}
+
+	sessionMutex.Lock()
+	val, ok := sessions[sessionID]
+	sessionMutex.Unlock()
+
+	// permit recovering from a panic but return the error
+	defer func(s sessionInterface) {
+		if r := recover(); r != nil {
+			if handledError, _ := r.(error); handledError != nil {
+				handlePanic("batchJSON", sessionID, val, handledError)
+				err = handledError
+			}
+		}
+	}(val)
+
+	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
+		result, err = val.batchJSON(realOperations)
+	} else {
+		err = fmt.Errorf("sessionID %v does not exist", sessionID)
+	}
+
+	return result, err
+}
+
+// RPCPollBatch runs many jobs (across any number of Sessions) with a bounded pool of workers; batch is a JSON object like
+// {"workers": 16, "per_host": 1, "jobs": [{"session_id": 0, "operation": "walkBulk", "oids": [".1.3.6.1.2.1.2.2.1.2"]}]} and
+// the result is a JSON list of {"SessionID": 0, "Results": [...], "Error": "...", "Latency": 0.012} (one per job, in the same order)
+func RPCPollBatch(batch string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	realBatch, err := parsePollBatch(batch)
+	if err != nil {
+		return "[]", err
+	}
+
+	return pollBatchJSON(realBatch)
+}
+
+// RPCAddPollSchedule starts polling in the background and returns the scheduleID; schedule is a JSON object like a job for
+// RPCPollBatch with the addition of "interval" and "jitter" (in seconds), e.g.
+// {"session_id": 0, "operation": "walkBulk", "oids": [".1.3.6.1.2.1.2.2.1.10"], "interval": 60, "jitter": 5}
+func RPCAddPollSchedule(schedule string) (uint64, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	realSchedule, err := parsePollSchedule(schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	sessionMutex.Lock()
+	_, ok := sessions[realSchedule.SessionID]
+	sessionMutex.Unlock()
+
+	if !ok {
+		return 0, fmt.Errorf("sessionID %v does not exist", realSchedule.SessionID)
+	}
+
+	return addPollSchedule(realSchedule), nil
+}
+
+// RPCRemovePollSchedule stops the schedule identified by the scheduleID (waiting for any run in progress to finish)
+func RPCRemovePollSchedule(scheduleID uint64) error {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	if !removePollSchedule(scheduleID) {
+		return fmt.Errorf("scheduleID %v does not exist", scheduleID)
+	}
+
+	return nil
+}
+
+// RPCPollResults removes and returns up to maxResults (or all if maxResults <= 0) of the results of scheduled polls, oldest first,
+// as a JSON list like RPCPollBatch's with the addition of "ScheduleID", "ScheduledAt" and "StartedAt" (unix time in seconds)
+func RPCPollResults(maxResults int) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	resultsBytes, err := json.Marshal(drainPollResults(maxResults))
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(resultsBytes), nil
+}
+
+// RPCPollStats returns statistics for each scheduled poll (runs, errors, overruns, missed deadlines etc) and the result queue
+func RPCPollStats() (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	statsBytes, err := json.Marshal(getPollStats())
+	if err != nil {
+		return "{}", err
+	}
+
+	return string(statsBytes), nil
+}
+
+// RPCExportProfiles returns what's been learned about each device (across all sessions to it) as a JSON list like
+// [{"Target": "10.0.0.1:161", "MaxRepetitions": 15, "ResponseVarbinds": 15, "ResponseBytes": 1210.5, "Latency": 0.012, ...}]
+func RPCExportProfiles() (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	profilesBytes, err := json.Marshal(exportTargetProfiles())
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(profilesBytes), nil
+}
+
+// RPCImportProfiles adds profiles (as given by RPCExportProfiles) for new sessions to start from, replacing any already held for
+// the same devices; returns how many were imported
+func RPCImportProfiles(profiles string) (int, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	return importTargetProfiles(profiles)
+}
+
+// RPCProbe tries each of the candidate credential sets against the target until one can read sysObjectID and sysDescr; candidates
+// is JSON like {"order": ["3", "2c", "1"], "timeout": 1, "candidates": [{"version": "2c", "community": "public"}, ...]} (see
+// probeRequest) and the result is JSON like {"Success": true, "Candidate": 1, "Options": {...}, "SysObjectID": "...", "Failures": [...]}
+func RPCProbe(target string, candidates string) (result string, err error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	// permit recovering from a panic (the probe's sessions are its own, so there's no session to speak of) but return the error
+	defer func() {
+		if r := recover(); r != nil {
+			handledError, ok := r.(error)
+			if !ok {
+				handledError = fmt.Errorf("%v", r)
+			}
+
+			handlePanic("probeJSON", 0, nil, handledError)
+			result, err = "{}", handledError
+		}
+	}()
+
+	return probeJSON(target, candidates)
+}
+
+// RPCLoadMIBs parses every MIB module in the directory so that OIDs can be given by name (e.g. IF-MIB::ifInOctets.3) and results
+// annotated; returns JSON like {"Modules": ["IF-MIB", ...], "Errors": ["...: line 12: ..."], "Unresolved": ["IF-MIB::..."]}
+func RPCLoadMIBs(directory string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	result, err := loadMIBs(directory)
+	if err != nil {
+		return "{}", err
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return "{}", err
+	}
+
+	return string(resultBytes), nil
+}
+
+// RPCResolveOID turns a name like IF-MIB::ifInOctets.3 into a numeric OID (as per the loaded MIBs)
+func RPCResolveOID(name string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	if isNumericOID(name) {
+		return formatNumericOID(name), nil
+	}
+
+	return resolveOIDName(name)
+}
+
+// RPCLookupOID turns a numeric OID into a name like IF-MIB::ifInOctets.3 (as per the loaded MIBs; the OID as is if there's no name
+// for it)
+func RPCLookupOID(oid string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	if !isNumericOID(oid) {
+		return "", fmt.Errorf("oid %v is invalid; must be numeric", oid)
+	}
+
+	return formatOIDName(oid), nil
+}
+
+// RPCDecodeIndex splits an instance OID suffix (e.g. 10.0.0.1.22.10.0.0.2.5000) into its components as per the INDEX of the table;
+// table is the name of a table, row or column (e.g. RFC1213-MIB::tcpConnState, as per the loaded MIBs) or an inline spec, JSON like
+// [{"name": "ifIndex", "type": "integer"}, {"type": "string", "implied": true}] (see indexSpecComponent); returns JSON like
+// [{"Name": "tcpConnLocalAddress", "Type": "ipaddress", "Value": "10.0.0.1"}, ...]
+func RPCDecodeIndex(table string, index string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	return decodeIndexJSON(table, index)
+}
+
+// RPCEncodeIndex is the reverse of RPCDecodeIndex; values is a JSON list with a value for each component of the INDEX (e.g.
+// ["10.0.0.1", 22, "10.0.0.2", 5000]) and it returns the instance OID suffix (e.g. 10.0.0.1.22.10.0.0.2.5000)
+func RPCEncodeIndex(table string, values string) (string, error) {
+	tState := releaseGIL()
+	defer reacquireGIL(tState)
+
+	return encodeIndexJSON(table, values)
+}
+
 // RPCSetString calls .setString on the Session identified by the sessionID
-func RPCSetString(sessionID uint64, oid, value string) (string, error) {
+func RPCSetString(sessionID uint64, oid, value string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -320,6 +833,9 @@ func RPCSetString(sessionID uint64, oid, value string) (string, error) {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.setStringJSON(oid, value)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -329,7 +845,7 @@ func RPCSetString(sessionID uint64, oid, value string) (string, error) {
 }
 
 // RPCSetInteger calls .SetInteger on the Session identified by the sessionID
-func RPCSetInteger(sessionID uint64, oid string, value int) (string, error) {
+func RPCSetInteger(sessionID uint64, oid string, value int, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -351,6 +867,9 @@ func RPCSetInteger(sessionID uint64, oid string, value int) (string, error) {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.setIntegerJSON(oid, value)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -360,7 +879,7 @@ func RPCSetInteger(sessionID uint64, oid string, value int) (string, error) {
 }
 
 // RPCSetIPAddress calls .setIPAddress on the Session identified by the sessionID
-func RPCSetIPAddress(sessionID uint64, oid, value string) (string, error) {
+func RPCSetIPAddress(sessionID uint64, oid, value string, timeout float64, retries int) (string, error) {
 	tState := releaseGIL()
 	defer reacquireGIL(tState)
 
@@ -382,6 +901,9 @@ func RPCSetIPAddress(sessionID uint64, oid, value string) (string, error) {
 	}(val)
 
 	if ok {
+		val.lock()
+		defer val.unlock()
+		defer val.applyCallOverrides(secondsToDuration(timeout), retries)()
 		result, err = val.setIPAddressJSON(oid, value)
 	} else {
 		err = fmt.Errorf("sessionID %v does not exist", sessionID)
@@ -416,5 +938,12 @@ func RPCClose(sessionID uint64) error {
 		}
 	}(val)
 
+	removePollSchedulesForSession(sessionID)
+	forgetRates(sessionID)
+
+	// wait for anything in-flight to finish
+	val.lock()
+	defer val.unlock()
+
 	return val.close()
 }
@@ -0,0 +1,283 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const maxQueuedPollResults = 100000 // beyond this the oldest results are dropped
+
+// pollSchedule is a pollJob that's run every Interval seconds (each run delayed by a random amount of up to Jitter seconds)
+type pollSchedule struct {
+	pollJob
+	Interval float64 `json:"interval"`
+	Jitter   float64 `json:"jitter"`
+}
+
+type scheduledPollResult struct {
+	ScheduleID uint64
+	pollResult
+	ScheduledAt float64 // unix time (in seconds) the run was due
+	StartedAt   float64 // unix time (in seconds) the run actually started
+}
+
+type pollScheduleStats struct {
+	ScheduleID      uint64
+	SessionID       uint64
+	Interval        float64
+	Runs            int
+	Errors          int
+	Overruns        int     // runs that took longer than the interval
+	MissedDeadlines int     // runs that never happened because an earlier one was still going when they were due
+	MaxLateness     float64 // the most seconds any run started after it was due (not counting jitter)
+	LastLatency     float64
+}
+
+type pollStats struct {
+	Schedules []pollScheduleStats
+	Queued    int // results waiting to be drained by RPCPollResults
+	Dropped   int // results thrown away (oldest first) because the queue was full
+}
+
+type runningPollSchedule struct {
+	schedule pollSchedule
+	stop     chan struct{}
+	stopped  chan struct{}
+
+	mutex sync.Mutex // guards stats
+	stats pollScheduleStats
+}
+
+var pollScheduleMutex sync.Mutex
+var pollSchedules = make(map[uint64]*runningPollSchedule)
+var lastPollScheduleID uint64
+
+var pollResultMutex sync.Mutex
+var pollResultQueue = make([]scheduledPollResult, 0)
+var droppedPollResults int
+
+func parsePollSchedule(scheduleJSON string) (pollSchedule, error) {
+	schedule := pollSchedule{}
+
+	err := json.Unmarshal([]byte(scheduleJSON), &schedule)
+	if err != nil {
+		return pollSchedule{}, fmt.Errorf("failed to parse poll schedule: %v", err)
+	}
+
+	if secondsToDuration(schedule.Interval) < time.Millisecond {
+		return pollSchedule{}, fmt.Errorf("interval %v is invalid; must be at least 1ms", schedule.Interval)
+	}
+
+	if schedule.Jitter < 0 || schedule.Jitter >= schedule.Interval {
+		return pollSchedule{}, fmt.Errorf("jitter %v is invalid; must not be negative and must be less than the interval", schedule.Jitter)
+	}
+
+	if len(schedule.OIDs) == 0 {
+		return pollSchedule{}, fmt.Errorf("oids must be length of 1 or more")
+	}
+
+	err = schedule.WalkOptions.validate()
+	if err != nil {
+		return pollSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+func toUnixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func queuePollResult(result scheduledPollResult) {
+	pollResultMutex.Lock()
+	defer pollResultMutex.Unlock()
+
+	if len(pollResultQueue) >= maxQueuedPollResults {
+		pollResultQueue = pollResultQueue[1:]
+		droppedPollResults++
+	}
+
+	pollResultQueue = append(pollResultQueue, result)
+}
+
+// drainPollResults removes and returns up to maxResults of the queued results (all of them if maxResults <= 0), oldest first
+func drainPollResults(maxResults int) []scheduledPollResult {
+	pollResultMutex.Lock()
+	defer pollResultMutex.Unlock()
+
+	if maxResults <= 0 || maxResults > len(pollResultQueue) {
+		maxResults = len(pollResultQueue)
+	}
+
+	results := make([]scheduledPollResult, maxResults)
+	copy(results, pollResultQueue[:maxResults])
+
+	pollResultQueue = append(make([]scheduledPollResult, 0, len(pollResultQueue)-maxResults), pollResultQueue[maxResults:]...)
+
+	return results
+}
+
+// run calls the pollJob every interval until stopped; if a run overruns, the runs that should have happened in the meantime
+// are skipped (and counted) rather than being run back-to-back
+func (r *runningPollSchedule) run() {
+	defer close(r.stopped)
+
+	interval := secondsToDuration(r.schedule.Interval)
+	due := time.Now()
+
+	for {
+		jitter := time.Duration(0)
+		if r.schedule.Jitter > 0 {
+			jitterMutex.Lock()
+			jitter = time.Duration(jitterRandom.Int63n(int64(secondsToDuration(r.schedule.Jitter)) + 1))
+			jitterMutex.Unlock()
+		}
+
+		timer := time.NewTimer(time.Until(due.Add(jitter)))
+		select {
+		case <-r.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		startedAt := time.Now()
+
+		var result pollResult
+		s, ok := getSession(r.schedule.SessionID)
+		if ok {
+			result = runPollJob(r.schedule.pollJob, s)
+		} else {
+			result = pollResult{
+				SessionID: r.schedule.SessionID,
+				Results:   make([]multiResult, 0),
+				Error:     fmt.Sprintf("sessionID %v does not exist", r.schedule.SessionID),
+			}
+		}
+
+		queuePollResult(
+			scheduledPollResult{
+				ScheduleID:  r.stats.ScheduleID,
+				pollResult:  result,
+				ScheduledAt: toUnixSeconds(due),
+				StartedAt:   toUnixSeconds(startedAt),
+			},
+		)
+
+		now := time.Now()
+
+		r.mutex.Lock()
+		r.stats.Runs++
+		if result.Error != "" {
+			r.stats.Errors++
+		}
+		if now.Sub(startedAt) > interval {
+			r.stats.Overruns++
+		}
+		lateness := startedAt.Sub(due.Add(jitter)).Seconds()
+		if lateness > r.stats.MaxLateness {
+			r.stats.MaxLateness = lateness
+		}
+		r.stats.LastLatency = result.Latency
+
+		due = due.Add(interval)
+		for !due.After(now) {
+			due = due.Add(interval)
+			r.stats.MissedDeadlines++
+		}
+		r.mutex.Unlock()
+	}
+}
+
+func (r *runningPollSchedule) getStats() pollScheduleStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.stats
+}
+
+// addPollSchedule starts running the schedule in the background and returns the scheduleID it can be referred to by
+func addPollSchedule(schedule pollSchedule) uint64 {
+	pollScheduleMutex.Lock()
+	defer pollScheduleMutex.Unlock()
+
+	scheduleID := lastPollScheduleID
+	lastPollScheduleID++
+
+	r := &runningPollSchedule{
+		schedule: schedule,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		stats: pollScheduleStats{
+			ScheduleID: scheduleID,
+			SessionID:  schedule.SessionID,
+			Interval:   schedule.Interval,
+		},
+	}
+
+	pollSchedules[scheduleID] = r
+
+	go r.run()
+
+	return scheduleID
+}
+
+// removePollSchedule stops the schedule (waiting for any run in progress to finish); false if it doesn't exist
+func removePollSchedule(scheduleID uint64) bool {
+	pollScheduleMutex.Lock()
+	r, ok := pollSchedules[scheduleID]
+	delete(pollSchedules, scheduleID)
+	pollScheduleMutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	close(r.stop)
+	<-r.stopped
+
+	return true
+}
+
+// removePollSchedulesForSession stops all of the schedules for the session
+func removePollSchedulesForSession(sessionID uint64) {
+	scheduleIDs := make([]uint64, 0)
+
+	pollScheduleMutex.Lock()
+	for scheduleID, r := range pollSchedules {
+		if r.schedule.SessionID == sessionID {
+			scheduleIDs = append(scheduleIDs, scheduleID)
+		}
+	}
+	pollScheduleMutex.Unlock()
+
+	for _, scheduleID := range scheduleIDs {
+		removePollSchedule(scheduleID)
+	}
+}
+
+func getPollStats() pollStats {
+	stats := pollStats{
+		Schedules: make([]pollScheduleStats, 0),
+	}
+
+	pollScheduleMutex.Lock()
+	for _, r := range pollSchedules {
+		stats.Schedules = append(stats.Schedules, r.getStats())
+	}
+	pollScheduleMutex.Unlock()
+
+	sort.Slice(stats.Schedules, func(i, j int) bool {
+		return stats.Schedules[i].ScheduleID < stats.Schedules[j].ScheduleID
+	})
+
+	pollResultMutex.Lock()
+	stats.Queued = len(pollResultQueue)
+	stats.Dropped = droppedPollResults
+	pollResultMutex.Unlock()
+
+	return stats
+}
@@ -1,9 +1,9 @@
 package gosnmp_python_go
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ftpsolutions/gosnmp"
@@ -36,8 +36,8 @@ var usmIgnoreTypes = map[gosnmp.Asn1BER]bool{
 type multiResult struct {
 	OID              string
 	Type             string
-	IsNull           bool
-	IsUnknown        bool
+	IsNull           bool // Null; Type is null
+	IsUnknown        bool // a type we don't know what to do with; Type is unknown, IntValue is its tag and ByteArrayValue its contents
 	IsNoSuchInstance bool
 	IsNoSuchObject   bool
 	IsEndOfMibView   bool
@@ -46,6 +46,10 @@ type multiResult struct {
 	FloatValue       float64
 	ByteArrayValue   []int
 	StringValue      string
+	Name             string         `json:",omitempty"` // e.g. IF-MIB::ifInOctets, if the session annotates (see annotateMultiResults)
+	Index            string         `json:",omitempty"` // what follows the Name's OID, e.g. 3
+	DisplayValue     interface{}    `json:",omitempty"` // the value as per its MIB, e.g. up or 00:1a:2b:3c:4d:5e (a list of names for BITS)
+	valueType        gosnmp.Asn1BER // not serialised; used to tell counters from other integers
 }
 
 func getSecurityLevel(securityLevel string) gosnmp.SnmpV3MsgFlags {
@@ -62,6 +66,19 @@ func getSecurityLevel(securityLevel string) gosnmp.SnmpV3MsgFlags {
 	return actualSecurityLevel
 }
 
+func getVersion(version string) gosnmp.SnmpVersion {
+	actualVersion := gosnmp.Version2c
+
+	switch version {
+	case "1":
+		actualVersion = gosnmp.Version1
+	case "3":
+		actualVersion = gosnmp.Version3
+	}
+
+	return actualVersion
+}
+
 func getPrivacyDetails(privacyPassword, privacyProtocol string) (string, gosnmp.SnmpV3PrivProtocol) {
 	if privacyProtocol == "" {
 		privacyPassword = ""
@@ -108,15 +125,29 @@ func buildNoSuchInstanceMultiResult(oid string) multiResult {
 	}
 }
 
+// bytesToInts converts bytes to what goes in a multiResult's ByteArrayValue
+func bytesToInts(valueAsBytes []byte) []int {
+	valueAsInts := make([]int, len(valueAsBytes), len(valueAsBytes))
+
+	for i, c := range valueAsBytes {
+		valueAsInts[i] = int(c)
+	}
+
+	return valueAsInts
+}
+
 func buildMultiResult(oid string, valueType gosnmp.Asn1BER, value interface{}) (multiResult, error) {
 	multiResult := multiResult{
-		OID: oid,
+		OID:       oid,
+		valueType: valueType,
 	}
 
 	switch valueType {
 
 	case gosnmp.Null:
-		fallthrough
+		multiResult.Type = "null"
+		multiResult.IsNull = true
+		return multiResult, nil
 
 	case gosnmp.NoSuchInstance:
 		multiResult.Type = "noSuchInstance"
@@ -166,35 +197,60 @@ func buildMultiResult(oid string, valueType gosnmp.Asn1BER, value interface{}) (
 		multiResult.FloatValue = float64(value.(float32))
 		return multiResult, nil
 	case gosnmp.OpaqueDouble:
-		fallthrough
-	case gosnmp.Opaque:
 		multiResult.Type = "float"
 		multiResult.FloatValue = value.(float64)
 		return multiResult, nil
 
-	case gosnmp.OctetString:
-		multiResult.Type = "bytearray"
-
-		valueAsBytes := value.([]byte)
-		valueAsInts := make([]int, len(valueAsBytes), len(valueAsBytes))
-
-		for i, c := range valueAsBytes {
-			valueAsInts[i] = int(c)
+	case gosnmp.Opaque:
+		// an Opaque that isn't a float or a double is left as it came (see recoverUnknownValues); not for SNMPv3, where we can't get at
+		// what came and have only what gosnmp made of the contents
+		valueAsFloat, ok := value.(float64)
+		if ok {
+			multiResult.Type = "float"
+			multiResult.FloatValue = valueAsFloat
+			return multiResult, nil
 		}
 
-		multiResult.ByteArrayValue = valueAsInts
+		fallthrough
+	case gosnmp.OctetString:
+		fallthrough
+	case gosnmp.BitString:
+		fallthrough
+	case gosnmp.NsapAddress:
+		valueAsBytes, _ := value.([]byte)
+		multiResult.Type = "bytearray"
+		multiResult.ByteArrayValue = bytesToInts(valueAsBytes)
 		return multiResult, nil
 
 	case gosnmp.ObjectIdentifier:
 		fallthrough
 	case gosnmp.IPAddress:
+		// gosnmp gives no value for an IpAddress of zero length
+		valueAsString, _ := value.(string)
 		multiResult.Type = "string"
-		multiResult.StringValue = value.(string)
+		multiResult.StringValue = valueAsString
 		return multiResult, nil
 
 	}
 
-	return multiResult, fmt.Errorf("unknown type; oid=%v, type=%v, value=%v", oid, valueType, value)
+	// a type we don't know what to do with (or one gosnmp couldn't decode) rather than failing the whole result; the tag goes in
+	// IntValue and the contents in ByteArrayValue, as they came (if we have them, see recoverUnknownValues)
+	multiResult.Type = "unknown"
+	multiResult.IsUnknown = true
+
+	switch raw := value.(type) {
+	case rawValue:
+		multiResult.IntValue = int(raw.tag)
+		multiResult.ByteArrayValue = bytesToInts(raw.data)
+	case []byte:
+		multiResult.IntValue = int(valueType)
+		multiResult.ByteArrayValue = bytesToInts(raw)
+	default:
+		multiResult.IntValue = int(valueType)
+		multiResult.ByteArrayValue = make([]int, 0)
+	}
+
+	return multiResult, nil
 }
 
 func checkVariableCount(oid string, result *gosnmp.SnmpPacket) error {
@@ -290,31 +346,81 @@ func checkForSNMPv3Issues(oid string, result *gosnmp.SnmpPacket) error {
 	return nil
 }
 
+// buildMultiResults checks a result for errors and converts all of its variables
+func buildMultiResults(oid string, result *gosnmp.SnmpPacket) ([]multiResult, error) {
+	emptyMultiResults := make([]multiResult, 0)
+
+	if isNoSuchNameError(result) {
+		return []multiResult{buildNoSuchInstanceMultiResult(result.Variables[0].Name)}, nil
+	}
+
+	err := checkForErrors(result)
+	if err != nil {
+		return emptyMultiResults, err
+	}
+
+	err = checkForSNMPv3Issues(oid, result)
+	if err != nil {
+		return emptyMultiResults, err
+	}
+
+	multiResults := make([]multiResult, 0)
+	for _, variable := range result.Variables {
+		multiResult, err := buildMultiResult(
+			variable.Name,
+			variable.Type,
+			variable.Value,
+		)
+		if err != nil {
+			return emptyMultiResults, err
+		}
+
+		multiResults = append(multiResults, multiResult)
+	}
+
+	return multiResults, nil
+}
+
 type sessionInterface interface {
+	lock()
+	unlock()
 	getSNMP() *gosnmp.GoSNMP
 	connect() error
+	applyCallOverrides(time.Duration, int) func()
 	get(string) (multiResult, error)
 	getJSON(string) (string, error)
 	getNext(string) (multiResult, error)
 	getNextJSON(string) (string, error)
 	getBulk([]string, uint8, uint8) ([]multiResult, error)
 	getBulkJSON([]string, uint8, uint8) (string, error)
-	walk(string) ([]multiResult, error)
-	walkJSON(string) (string, error)
-	walkBulk(string) ([]multiResult, error)
-	walkBulkJSON(string) (string, error)
+	getBulkGrouped([]string, uint8, uint8) ([]bulkGroup, error)
+	getBulkGroupedJSON([]string, uint8, uint8) (string, error)
+	walk(string, walkOptions) ([]multiResult, error)
+	walkJSON(string, walkOptions) (string, error)
+	walkBulk(string, walkOptions) ([]multiResult, error)
+	walkBulkJSON(string, walkOptions) (string, error)
+	walkMany([]string, walkOptions) ([]multiResult, error)
+	walkManyJSON([]string, walkOptions) (string, error)
 	setString(string, string) (multiResult, error)
 	setStringJSON(string, string) (string, error)
 	setInteger(string, int) (multiResult, error)
 	setIntegerJSON(string, int) (string, error)
 	setIPAddress(string, string) (multiResult, error)
 	setIPAddressJSON(string, string) (string, error)
+	setMany([]string, []string, []string) ([]multiResult, error)
+	setManyJSON([]string, []string, []string) (string, error)
+	batch([]batchOperation) []batchResult
+	batchJSON([]batchOperation) (string, error)
+	poll(string, []string, uint8, uint8, walkOptions) ([]multiResult, error)
 	close() error
 }
 
 type session struct {
+	mutex     sync.Mutex // serialises operations (RPCs and polls, see runPollJob); gosnmp (and our wrapper) keep per-request state
 	snmp      wrappedSNMPInterface
-	connected bool // used to avoid weird memory errors if the underlying connect fails (snmp object left in insane state)
+	connected bool   // used to avoid weird memory errors if the underlying connect fails (snmp object left in insane state)
+	encoding  string // how results are returned to Python; json or binary
+	annotate  bool   // results are given their symbolic names (see annotateMultiResults)
 }
 
 func getLogger(snmpProtocol, hostname string, port int) *log.Logger {
@@ -332,6 +438,10 @@ func getLogger(snmpProtocol, hostname string, port int) *log.Logger {
 		return nil
 	}
 
+	return newLogger(snmpProtocol, hostname, port)
+}
+
+func newLogger(snmpProtocol, hostname string, port int) *log.Logger {
 	return log.New(
 		os.Stdout,
 		fmt.Sprintf("%v:%v:%v\t", snmpProtocol, hostname, port),
@@ -339,106 +449,137 @@ func getLogger(snmpProtocol, hostname string, port int) *log.Logger {
 	)
 }
 
-func newSessionV1(hostname string, port int, community string, timeout, retries int) session {
-	snmp := wrappedSNMP{
-		&gosnmp.GoSNMP{
-			Target:         hostname,
-			Port:           uint16(port),
-			Community:      community,
-			Version:        gosnmp.Version1,
-			Timeout:        time.Duration(timeout) * time.Second,
-			Retries:        retries,
-			MaxOids:        maxOids,
-			MaxRepetitions: defaultMaxRepetitions,
-		},
-		0,
-		0,
-		time.Time{},
-		0,
-	}
+func newSessionV1(hostname string, port int, community string, timeout float64, retries int, localAddress string, localPort int, localInterface string) *session {
+	options := getDefaultSessionOptions()
+	options.Version = "1"
+	options.Hostname = hostname
+	options.Port = port
+	options.Community = community
+	options.Timeout = timeout
+	options.Retries = retries
+	options.LocalAddress = localAddress
+	options.LocalPort = localPort
+	options.LocalInterface = localInterface
 
-	logger := getLogger("SNMPv1", hostname, port)
-	if logger != nil {
-		snmp.snmp.Logger = logger
-	}
+	return newSession(options)
+}
 
-	s := session{
-		snmp: &snmp,
-	}
+func newSessionV2c(hostname string, port int, community string, timeout float64, retries int, localAddress string, localPort int, localInterface string) *session {
+	options := getDefaultSessionOptions()
+	options.Version = "2c"
+	options.Hostname = hostname
+	options.Port = port
+	options.Community = community
+	options.Timeout = timeout
+	options.Retries = retries
+	options.LocalAddress = localAddress
+	options.LocalPort = localPort
+	options.LocalInterface = localInterface
 
-	return s
+	return newSession(options)
 }
 
-func newSessionV2c(hostname string, port int, community string, timeout, retries int) session {
-	snmp := wrappedSNMP{
-		&gosnmp.GoSNMP{
-			Target:         hostname,
-			Port:           uint16(port),
-			Community:      community,
-			Version:        gosnmp.Version2c,
-			Timeout:        time.Duration(timeout) * time.Second,
-			Retries:        retries,
-			MaxOids:        maxOids,
-			MaxRepetitions: defaultMaxRepetitions,
-		},
-		0,
-		0,
-		time.Time{},
-		0,
-	}
+func newSessionV3(hostname string, port int, contextName, securityUsername, privacyPassword, authPassword, securityLevel, authProtocol, privacyProtocol string, timeout float64, retries int, localAddress string, localPort int, localInterface string) *session {
+	options := getDefaultSessionOptions()
+	options.Version = "3"
+	options.Hostname = hostname
+	options.Port = port
+	options.ContextName = contextName
+	options.SecurityUsername = securityUsername
+	options.PrivacyPassword = privacyPassword
+	options.AuthPassword = authPassword
+	options.SecurityLevel = securityLevel
+	options.AuthProtocol = authProtocol
+	options.PrivacyProtocol = privacyProtocol
+	options.Timeout = timeout
+	options.Retries = retries
+	options.LocalAddress = localAddress
+	options.LocalPort = localPort
+	options.LocalInterface = localInterface
 
-	logger := getLogger("SNMPv2c", hostname, port)
-	if logger != nil {
-		snmp.snmp.Logger = logger
-	}
+	return newSession(options)
+}
 
-	s := session{
-		snmp: &snmp,
+// newSessionFromOptions validates the given options before building a session from them
+func newSessionFromOptions(options sessionOptions) (*session, error) {
+	err := options.validate()
+	if err != nil {
+		return nil, err
 	}
 
-	return s
+	return newSession(options), nil
 }
 
-func newSessionV3(hostname string, port int, contextName, securityUsername, privacyPassword, authPassword, securityLevel, authProtocol, privacyProtocol string, timeout, retries int) session {
-	actualAuthPassword, actualAuthProtocol := getAuthenticationDetails(authPassword, authProtocol)
-	actualPrivPassword, actualPrivProtocol := getPrivacyDetails(privacyPassword, privacyProtocol)
+// newSession builds a session from options that are assumed to be sane (see sessionOptions.validate)
+func newSession(options sessionOptions) *session {
+	maxRepetitions := options.MaxRepetitions
+	if maxRepetitions == 0 {
+		maxRepetitions = defaultMaxRepetitions
+	}
 
 	snmp := wrappedSNMP{
-		&gosnmp.GoSNMP{
-			Target:        hostname,
-			Port:          uint16(port),
-			Version:       gosnmp.Version3,
-			Timeout:       time.Duration(timeout) * time.Second,
-			Retries:       retries,
-			SecurityModel: gosnmp.UserSecurityModel,
-			MsgFlags:      getSecurityLevel(securityLevel),
-			SecurityParameters: &gosnmp.UsmSecurityParameters{
-				UserName:                 securityUsername,
-				AuthenticationPassphrase: actualAuthPassword,
-				AuthenticationProtocol:   actualAuthProtocol,
-				PrivacyPassphrase:        actualPrivPassword,
-				PrivacyProtocol:          actualPrivProtocol,
-			},
-			ContextName:    contextName,
-			MaxOids:        maxOids,
-			MaxRepetitions: defaultMaxRepetitions,
+		snmp: &gosnmp.GoSNMP{
+			Target:         options.Hostname,
+			Port:           uint16(options.Port),
+			Community:      options.Community,
+			Version:        getVersion(options.Version),
+			Timeout:        options.getTimeout(),
+			Retries:        options.Retries,
+			MaxOids:        options.MaxOids,
+			MaxRepetitions: uint8(maxRepetitions),
 		},
-		0,
-		0,
-		time.Time{},
-		0,
+		transport:           options.Transport,
+		retryPolicy:         options.RetryPolicy,
+		pipelineDepth:       options.PipelineDepth,
+		localAddress:        options.LocalAddress,
+		localPort:           options.LocalPort,
+		localInterface:      options.LocalInterface,
+		maxMessageSize:      options.MaxMessageSize,
+		emulateGetBulk:      options.EmulateGetBulk,
+		maxRepetitionsGiven: options.MaxRepetitions != 0,
+	}
+
+	if snmp.snmp.Version == gosnmp.Version3 {
+		actualAuthPassword, actualAuthProtocol := getAuthenticationDetails(options.AuthPassword, options.AuthProtocol)
+		actualPrivPassword, actualPrivProtocol := getPrivacyDetails(options.PrivacyPassword, options.PrivacyProtocol)
+
+		snmp.snmp.SecurityModel = gosnmp.UserSecurityModel
+		snmp.snmp.MsgFlags = getSecurityLevel(options.SecurityLevel)
+		snmp.snmp.SecurityParameters = &gosnmp.UsmSecurityParameters{
+			UserName:                 options.SecurityUsername,
+			AuthenticationPassphrase: actualAuthPassword,
+			AuthenticationProtocol:   actualAuthProtocol,
+			PrivacyPassphrase:        actualPrivPassword,
+			PrivacyProtocol:          actualPrivProtocol,
+		}
+		snmp.snmp.ContextName = options.ContextName
+	}
+
+	logger := getLogger(options.getProtocolName(), options.Hostname, options.Port)
+	if logger == nil && options.Logging {
+		logger = newLogger(options.getProtocolName(), options.Hostname, options.Port)
 	}
 
-	logger := getLogger("SNMPv3", hostname, port)
 	if logger != nil {
 		snmp.snmp.Logger = logger
 	}
 
 	s := session{
-		snmp: &snmp,
+		snmp:     &snmp,
+		encoding: options.Encoding,
+		annotate: options.Annotate,
 	}
 
-	return s
+	return &s
+}
+
+// lock must be held by callers for the duration of any operation (including applyCallOverrides and what it applies to)
+func (s *session) lock() {
+	s.mutex.Lock()
+}
+
+func (s *session) unlock() {
+	s.mutex.Unlock()
 }
 
 func (s *session) getSNMP() *gosnmp.GoSNMP {
@@ -457,6 +598,15 @@ func (s *session) connect() error {
 	return err
 }
 
+// applyCallOverrides overrides the timeout (if > 0) and retries (if >= 0) until the returned func is called
+func (s *session) applyCallOverrides(timeout time.Duration, retries int) func() {
+	if s.snmp == nil {
+		return func() {}
+	}
+
+	return s.snmp.applyCallOverrides(timeout, retries)
+}
+
 func (s *session) get(oid string) (multiResult, error) {
 	emptyMultiResult := multiResult{}
 
@@ -497,7 +647,7 @@ func (s *session) getJSON(oid string) (string, error) {
 		return "{}", err
 	}
 
-	multiResultBytes, err := json.Marshal(multiResult)
+	multiResultBytes, err := s.marshalMultiResult(multiResult)
 	if err != nil {
 		return "{}", err
 	}
@@ -550,7 +700,7 @@ func (s *session) getNextJSON(oid string) (string, error) {
 		return "{}", err
 	}
 
-	multiResultBytes, err := json.Marshal(multiResult)
+	multiResultBytes, err := s.marshalMultiResult(multiResult)
 	if err != nil {
 		return "{}", err
 	}
@@ -570,35 +720,7 @@ func (s *session) getBulk(oids []string, nonRepeaters uint8, maxRepetitions uint
 		return emptyMultiResults, err
 	}
 
-	if isNoSuchNameError(result) {
-		return []multiResult{buildNoSuchInstanceMultiResult(result.Variables[0].Name)}, nil
-	}
-
-	err = checkForErrors(result)
-	if err != nil {
-		return emptyMultiResults, err
-	}
-
-	err = checkForSNMPv3Issues(oids[0], result)
-	if err != nil {
-		return emptyMultiResults, err
-	}
-
-	multiResults := make([]multiResult, 0)
-	for _, variable := range result.Variables {
-		multiResult, err := buildMultiResult(
-			variable.Name,
-			variable.Type,
-			variable.Value,
-		)
-		if err != nil {
-			return emptyMultiResults, err
-		}
-
-		multiResults = append(multiResults, multiResult)
-	}
-
-	return multiResults, nil
+	return buildMultiResults(oids[0], result)
 }
 
 func (s *session) getBulkJSON(oids []string, nonRepeaters uint8, maxRepetitions uint8) (string, error) {
@@ -607,7 +729,7 @@ func (s *session) getBulkJSON(oids []string, nonRepeaters uint8, maxRepetitions
 		return "[]", err
 	}
 
-	multiResultsBytes, err := json.Marshal(multiResults)
+	multiResultsBytes, err := s.marshalMultiResults(multiResults)
 	if err != nil {
 		return "[]", err
 	}
@@ -615,52 +737,57 @@ func (s *session) getBulkJSON(oids []string, nonRepeaters uint8, maxRepetitions
 	return string(multiResultsBytes), nil
 }
 
-func (s *session) walk(oid string) ([]multiResult, error) {
-	emptyMultiResults := make([]multiResult, 0)
-
-	result, err := s.snmp.walk([]string{oid})
+func (s *session) walk(oid string, options walkOptions) ([]multiResult, error) {
+	result, err := s.snmp.walk([]string{oid}, options)
 	if err != nil {
-		return emptyMultiResults, err
+		return buildPartialWalkResults(oid, result, options, err)
 	}
 
-	if isNoSuchNameError(result) {
-		return []multiResult{buildNoSuchInstanceMultiResult(result.Variables[0].Name)}, nil
+	return buildMultiResults(oid, result)
+}
+
+func (s *session) walkJSON(oid string, options walkOptions) (string, error) {
+	multiResults, err := s.walk(oid, options)
+	if options.Partial {
+		s.annotateMultiResults(multiResults)
+
+		return marshalPartialWalkResult(multiResults, err, options)
 	}
 
-	err = checkForErrors(result)
 	if err != nil {
-		return emptyMultiResults, err
+		return "[]", err
 	}
 
-	err = checkForSNMPv3Issues(oid, result)
+	multiResultsBytes, err := s.marshalMultiResults(multiResults)
 	if err != nil {
-		return emptyMultiResults, err
+		return "[]", err
 	}
 
-	multiResults := make([]multiResult, 0)
-	for _, variable := range result.Variables {
-		multiResult, err := buildMultiResult(
-			variable.Name,
-			variable.Type,
-			variable.Value,
-		)
-		if err != nil {
-			return emptyMultiResults, err
-		}
+	return string(multiResultsBytes), nil
+}
 
-		multiResults = append(multiResults, multiResult)
+func (s *session) walkBulk(oid string, options walkOptions) ([]multiResult, error) {
+	result, err := s.snmp.walkBulk([]string{oid}, options)
+	if err != nil {
+		return buildPartialWalkResults(oid, result, options, err)
 	}
 
-	return multiResults, nil
+	return buildMultiResults(oid, result)
 }
 
-func (s *session) walkJSON(oid string) (string, error) {
-	multiResults, err := s.walk(oid)
+func (s *session) walkBulkJSON(oid string, options walkOptions) (string, error) {
+	multiResults, err := s.walkBulk(oid, options)
+	if options.Partial {
+		s.annotateMultiResults(multiResults)
+
+		return marshalPartialWalkResult(multiResults, err, options)
+	}
+
 	if err != nil {
 		return "[]", err
 	}
 
-	multiResultsBytes, err := json.Marshal(multiResults)
+	multiResultsBytes, err := s.marshalMultiResults(multiResults)
 	if err != nil {
 		return "[]", err
 	}
@@ -668,52 +795,35 @@ func (s *session) walkJSON(oid string) (string, error) {
 	return string(multiResultsBytes), nil
 }
 
-func (s *session) walkBulk(oid string) ([]multiResult, error) {
+// walkMany walks all of the oids at once (see wrappedSNMP.walkMany) and returns the results for each oid in turn
+func (s *session) walkMany(oids []string, options walkOptions) ([]multiResult, error) {
 	emptyMultiResults := make([]multiResult, 0)
 
-	result, err := s.snmp.walkBulk([]string{oid})
-	if err != nil {
-		return emptyMultiResults, err
-	}
-
-	if isNoSuchNameError(result) {
-		return []multiResult{buildNoSuchInstanceMultiResult(result.Variables[0].Name)}, nil
-	}
-
-	err = checkForErrors(result)
-	if err != nil {
-		return emptyMultiResults, err
-	}
-
-	err = checkForSNMPv3Issues(oid, result)
+	results, err := s.snmp.walkMany(oids, options)
 	if err != nil {
 		return emptyMultiResults, err
 	}
 
 	multiResults := make([]multiResult, 0)
-	for _, variable := range result.Variables {
-		multiResult, err := buildMultiResult(
-			variable.Name,
-			variable.Type,
-			variable.Value,
-		)
+	for i, result := range results {
+		theseMultiResults, err := buildMultiResults(oids[i], result)
 		if err != nil {
 			return emptyMultiResults, err
 		}
 
-		multiResults = append(multiResults, multiResult)
+		multiResults = append(multiResults, theseMultiResults...)
 	}
 
 	return multiResults, nil
 }
 
-func (s *session) walkBulkJSON(oid string) (string, error) {
-	multiResults, err := s.walkBulk(oid)
+func (s *session) walkManyJSON(oids []string, options walkOptions) (string, error) {
+	multiResults, err := s.walkMany(oids, options)
 	if err != nil {
 		return "[]", err
 	}
 
-	multiResultsBytes, err := json.Marshal(multiResults)
+	multiResultsBytes, err := s.marshalMultiResults(multiResults)
 	if err != nil {
 		return "[]", err
 	}
@@ -767,7 +877,7 @@ func (s *session) setStringJSON(oid, value string) (string, error) {
 		return "{}", err
 	}
 
-	multiResultBytes, err := json.Marshal(multiResult)
+	multiResultBytes, err := s.marshalMultiResult(multiResult)
 	if err != nil {
 		return "{}", err
 	}
@@ -821,7 +931,7 @@ func (s *session) setIntegerJSON(oid string, value int) (string, error) {
 		return "{}", err
 	}
 
-	multiResultBytes, err := json.Marshal(multiResult)
+	multiResultBytes, err := s.marshalMultiResult(multiResult)
 	if err != nil {
 		return "{}", err
 	}
@@ -875,7 +985,7 @@ func (s *session) setIPAddressJSON(oid, value string) (string, error) {
 		return "{}", err
 	}
 
-	multiResultBytes, err := json.Marshal(multiResult)
+	multiResultBytes, err := s.marshalMultiResult(multiResult)
 	if err != nil {
 		return "{}", err
 	}
@@ -0,0 +1,201 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testIfTableRows = 20
+
+func newTestSession(t *testing.T, a *testAgent, options string) uint64 {
+	sessionID, err := NewRPCSessionFromOptions(
+		fmt.Sprintf(`{"hostname": "127.0.0.1", "port": %d, "timeout": 2, "retries": 1%v}`, a.port(), options),
+	)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	err = RPCConnect(sessionID)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	return sessionID
+}
+
+func countMultiResults(t *testing.T, resultJSON string) int {
+	multiResults := make([]multiResult, 0)
+
+	err := json.Unmarshal([]byte(resultJSON), &multiResults)
+	if err != nil {
+		t.Errorf("failed to parse %v: %v", resultJSON, err)
+	}
+
+	return len(multiResults)
+}
+
+func waitForPollResults(t *testing.T, timeout time.Duration) []scheduledPollResult {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		results := drainPollResults(0)
+		if len(results) > 0 {
+			return results
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("no scheduled poll results after %v", timeout)
+
+	return nil
+}
+
+// run with -race; every route to a session (the RPCs, RPCPollBatch and scheduled polls) is used at once
+func TestSessionConcurrentUse(t *testing.T) {
+	a := newTestAgent(t, testIfTable(testIfTableRows))
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	scheduleID, err := RPCAddPollSchedule(
+		fmt.Sprintf(`{"session_id": %d, "operation": "walkBulk", "oids": [".1.3.6.1.2.1.2.2"], "interval": 0.01}`, sessionID),
+	)
+	if err != nil {
+		t.Fatalf("failed to add poll schedule: %v", err)
+	}
+
+	batch := fmt.Sprintf(
+		`{"workers": 4, "per_host": 4, "jobs": [{"session_id": %d, "operation": "get", "oids": [".1.3.6.1.2.1.1.1.0"]}, `+
+			`{"session_id": %d, "operation": "walk", "oids": [".1.3.6.1.2.1.2.2.1.2"], "rates": true}]}`,
+		sessionID,
+		sessionID,
+	)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 10; i++ {
+				var result string
+				var err error
+				expected := 0
+
+				switch (g + i) % 4 {
+				case 0:
+					result, err = RPCGet(sessionID, ".1.3.6.1.2.1.1.3.0", 0, -1)
+					result, expected = "["+result+"]", 1
+				case 1:
+					result, err = RPCWalk(sessionID, ".1.3.6.1.2.1.2.2.1.1", 0, -1)
+					expected = testIfTableRows
+				case 2:
+					result, err = RPCWalkBulk(sessionID, ".1.3.6.1.2.1.2.2", 0, -1)
+					expected = testIfTableRows * 3
+				case 3:
+					result, err = RPCPollBatch(batch)
+					pollResults := make([]pollResult, 0)
+					_ = json.Unmarshal([]byte(result), &pollResults)
+					for _, pollResult := range pollResults {
+						if pollResult.Error != "" {
+							t.Errorf("poll failed: %v", pollResult.Error)
+						}
+					}
+					if len(pollResults) != 2 || len(pollResults[0].Results) != 1 || len(pollResults[1].Results) != testIfTableRows {
+						t.Errorf("unexpected poll results: %v", result)
+					}
+					continue
+				}
+
+				if err != nil {
+					t.Errorf("request failed: %v", err)
+					continue
+				}
+
+				count := countMultiResults(t, result)
+				if count != expected {
+					t.Errorf("expected %v results, got %v", expected, count)
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	err = RPCRemovePollSchedule(scheduleID)
+	if err != nil {
+		t.Fatalf("failed to remove poll schedule: %v", err)
+	}
+
+	for _, result := range drainPollResults(0) {
+		if result.Error != "" || len(result.Results) != testIfTableRows*3 {
+			t.Errorf("unexpected scheduled poll result: %+v", result)
+		}
+	}
+}
+
+// polls (batched or scheduled) must wait for whatever else holds the session
+func TestPollsTakeSessionLock(t *testing.T) {
+	a := newTestAgent(t, testIfTable(testIfTableRows))
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	s, ok := getSession(sessionID)
+	if !ok {
+		t.Fatalf("session %v does not exist", sessionID)
+	}
+
+	before := a.getRequests()
+
+	s.lock()
+
+	done := make(chan string)
+	go func() {
+		result, _ := RPCPollBatch(fmt.Sprintf(`{"jobs": [{"session_id": %d, "operation": "get", "oids": [".1.3.6.1.2.1.1.1.0"]}]}`, sessionID))
+		done <- result
+	}()
+
+	_ = drainPollResults(0)
+	scheduleID, err := RPCAddPollSchedule(
+		fmt.Sprintf(`{"session_id": %d, "operation": "get", "oids": [".1.3.6.1.2.1.1.1.0"], "interval": 0.01}`, sessionID),
+	)
+	if err != nil {
+		s.unlock()
+		t.Fatalf("failed to add poll schedule: %v", err)
+	}
+	defer func() {
+		_ = RPCRemovePollSchedule(scheduleID)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+
+	select {
+	case result := <-done:
+		t.Errorf("poll batch finished while the session was locked: %v", result)
+	default:
+	}
+
+	if len(drainPollResults(0)) != 0 || a.getRequests() != before {
+		t.Errorf("scheduled poll ran while the session was locked")
+	}
+
+	s.unlock()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("poll batch didn't finish once the session was unlocked")
+	}
+
+	waitForPollResults(t, 5*time.Second)
+}
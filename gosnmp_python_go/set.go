@@ -0,0 +1,142 @@
+package gosnmp_python_go
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// the INTEGER values gosnmp encodes correctly in a SET; it uses one octet up to 255 and two up to 65535 without allowing for the sign
+// bit (so 128 to 255 and 32768 to 65535 go out as negative numbers) and can't encode anything else at all
+const (
+	minSetInteger        = -128
+	maxSetInteger        = 32767
+	minSetIntegerTwoByte = 256 // 128 to 255 (in one octet) have the sign bit set
+)
+
+// buildSetPDU builds a PDU for an SNMP set from a value given as a string along with the name of its type
+func buildSetPDU(oid, valueType, value string) (gosnmp.SnmpPDU, error) {
+	pdu := gosnmp.SnmpPDU{}
+
+	var err error
+
+	pdu.Name, err = formatOID(oid)
+	if err != nil {
+		return pdu, err
+	}
+
+	switch strings.ToLower(valueType) {
+	case "integer", "int":
+		intValue, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return pdu, fmt.Errorf("value %#v for %v is not a valid integer: %v", value, oid, err)
+		}
+
+		if intValue < minSetInteger || intValue > maxSetInteger || (intValue > math.MaxInt8 && intValue < minSetIntegerTwoByte) {
+			return pdu, fmt.Errorf(
+				"value %v for %v can't be set; gosnmp can only encode integers from %v to %v and %v to %v",
+				intValue,
+				oid,
+				minSetInteger,
+				math.MaxInt8,
+				minSetIntegerTwoByte,
+				maxSetInteger,
+			)
+		}
+
+		pdu.Type = gosnmp.Integer
+		pdu.Value = int(intValue)
+	case "gauge32", "unsigned32":
+		uintValue, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return pdu, fmt.Errorf("value %#v for %v is not a valid %v: %v", value, oid, valueType, err)
+		}
+
+		pdu.Type = gosnmp.Gauge32
+		pdu.Value = uint32(uintValue)
+	case "octetstring", "string":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = value
+	case "hexstring":
+		bytesValue, err := hex.DecodeString(strings.NewReplacer(":", "", " ", "", "-", "").Replace(value))
+		if err != nil {
+			return pdu, fmt.Errorf("value %#v for %v is not a valid hexstring: %v", value, oid, err)
+		}
+
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = bytesValue
+	case "ipaddress":
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() == nil {
+			return pdu, fmt.Errorf("value %#v for %v is not a valid IPv4 address", value, oid)
+		}
+
+		pdu.Type = gosnmp.IPAddress
+		pdu.Value = value
+	case "counter32", "timeticks", "objectidentifier", "oid":
+		// gosnmp refuses to send them
+		return pdu, fmt.Errorf("type %#v for %v can't be set; gosnmp only supports SETs of integer, gauge32, octetstring and ipaddress", valueType, oid)
+	default:
+		return pdu, fmt.Errorf(
+			"type %#v for %v is invalid; must be one of integer, gauge32, unsigned32, octetstring, hexstring or ipaddress",
+			valueType,
+			oid,
+		)
+	}
+
+	return pdu, nil
+}
+
+// setMany sets all of the given varbinds in a single request; oids, valueTypes and values must all be the same length
+func (s *session) setMany(oids, valueTypes, values []string) ([]multiResult, error) {
+	emptyMultiResults := make([]multiResult, 0)
+
+	if len(oids) == 0 {
+		return emptyMultiResults, fmt.Errorf("oids must be length of 1 or more")
+	}
+
+	if len(valueTypes) != len(oids) || len(values) != len(oids) {
+		return emptyMultiResults, fmt.Errorf(
+			"oids, types and values must all be the same length (got %v, %v and %v)",
+			len(oids),
+			len(valueTypes),
+			len(values),
+		)
+	}
+
+	pdus := make([]gosnmp.SnmpPDU, 0)
+	for i, oid := range oids {
+		pdu, err := buildSetPDU(oid, valueTypes[i], values[i])
+		if err != nil {
+			return emptyMultiResults, err
+		}
+
+		pdus = append(pdus, pdu)
+	}
+
+	result, err := s.snmp.set(pdus)
+	if err != nil {
+		return emptyMultiResults, err
+	}
+
+	return buildMultiResults(oids[0], result)
+}
+
+func (s *session) setManyJSON(oids, valueTypes, values []string) (string, error) {
+	multiResults, err := s.setMany(oids, valueTypes, values)
+	if err != nil {
+		return "[]", err
+	}
+
+	multiResultsBytes, err := s.marshalMultiResults(multiResults)
+	if err != nil {
+		return "[]", err
+	}
+
+	return string(multiResultsBytes), nil
+}
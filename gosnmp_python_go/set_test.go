@@ -0,0 +1,90 @@
+package gosnmp_python_go
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSetMany(t *testing.T) {
+	const base = ".1.3.6.1.4.1.99999.20"
+
+	a := newTestAgent(t, nil)
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	s, _ := getSession(sessionID)
+
+	tests := []struct {
+		valueType string
+		value     string
+		expected  multiResult
+	}{
+		{"integer", "0", multiResult{Type: "int", IntValue: 0}},
+		{"integer", "127", multiResult{Type: "int", IntValue: 127}},
+		{"integer", "-128", multiResult{Type: "int", IntValue: -128}},
+		{"integer", "256", multiResult{Type: "int", IntValue: 256}},
+		{"integer", "32767", multiResult{Type: "int", IntValue: 32767}},
+		{"gauge32", "4294967295", multiResult{Type: "int", IntValue: 4294967295}},
+		{"octetstring", "hi", multiResult{Type: "bytearray", ByteArrayValue: []int{'h', 'i'}}},
+		{"hexstring", "00:1a:ff", multiResult{Type: "bytearray", ByteArrayValue: []int{0x00, 0x1a, 0xff}}},
+		{"ipaddress", "10.0.0.1", multiResult{Type: "string", StringValue: "10.0.0.1"}},
+	}
+
+	for i, test := range tests {
+		oid := base + "." + strconv.Itoa(i+1)
+
+		_, err := s.setMany([]string{oid}, []string{test.valueType}, []string{test.value})
+		if err != nil {
+			t.Errorf("%v %v: failed to set: %v", test.valueType, test.value, err)
+			continue
+		}
+
+		// read back what the agent got
+		got, err := s.get(oid)
+		if err != nil {
+			t.Errorf("%v %v: failed to get: %v", test.valueType, test.value, err)
+			continue
+		}
+
+		got.valueType = 0
+		expected := test.expected
+		expected.OID = oid
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v %v: expected %+v, got %+v", test.valueType, test.value, expected, got)
+		}
+	}
+}
+
+func TestBuildSetPDU(t *testing.T) {
+	const oid = ".1.3.6.1.4.1.99999.20.1"
+
+	tests := []struct {
+		valueType   string
+		value       string
+		expectError string
+	}{
+		{"integer", "-129", "value -129 for " + oid + " can't be set; gosnmp can only encode integers from -128 to 127 and 256 to 32767"},
+		{"integer", "200", "value 200 for " + oid + " can't be set; gosnmp can only encode integers from -128 to 127 and 256 to 32767"},
+		{"integer", "40000", "value 40000 for " + oid + " can't be set; gosnmp can only encode integers from -128 to 127 and 256 to 32767"},
+		{"integer", "2147483647", "value 2147483647 for " + oid + " can't be set; gosnmp can only encode integers from -128 to 127 and 256 to 32767"},
+		{"integer", "x", `value "x" for ` + oid + ` is not a valid integer: strconv.ParseInt: parsing "x": invalid syntax`},
+		{"counter32", "1", `type "counter32" for ` + oid + ` can't be set; gosnmp only supports SETs of integer, gauge32, octetstring and ipaddress`},
+		{"timeticks", "1", `type "timeticks" for ` + oid + ` can't be set; gosnmp only supports SETs of integer, gauge32, octetstring and ipaddress`},
+		{"oid", ".1.3", `type "oid" for ` + oid + ` can't be set; gosnmp only supports SETs of integer, gauge32, octetstring and ipaddress`},
+		{"ipaddress", "::1", `value "::1" for ` + oid + ` is not a valid IPv4 address`},
+		{"float", "1.5", `type "float" for ` + oid + ` is invalid; must be one of integer, gauge32, unsigned32, octetstring, hexstring or ipaddress`},
+	}
+
+	for _, test := range tests {
+		_, err := buildSetPDU(oid, test.valueType, test.value)
+		if err == nil || err.Error() != test.expectError {
+			t.Errorf("%v %v: expected %v, got %v", test.valueType, test.value, test.expectError, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package gosnmp_python_go
+
+import (
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// measuringConn notes the size of each datagram read (and, for SNMPv3, the msgMaxSize the agent advertises in it) so that GetBulk
+// can be sized to fit (see getFittingMaxRepetitions); it also notes tooBig responses, as gosnmp drops those that have no varbinds
+// (which is how RFC 3416 has them) and we'd otherwise only see a timeout; and it keeps the last datagram read so that what gosnmp
+// couldn't decode can be recovered (see recoverUnknownValues)
+type measuringConn struct {
+	net.PacketConn
+	lastReadSize  int64
+	agentMaxSize  int64
+	tooBig        int32
+	isV3          bool   // only SNMPv3 messages carry msgMaxSize (and their PDUs may be encrypted, so tooBig isn't looked for)
+	lastRead      []byte // a copy, as gosnmp reuses its buffer
+	lastReadMutex sync.Mutex
+}
+
+func (c *measuringConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	if err != nil {
+		return n, addr, err
+	}
+
+	atomic.StoreInt64(&c.lastReadSize, int64(n))
+
+	if c.isV3 {
+		maxSize, ok := parseMsgMaxSize(b[:n])
+		if ok {
+			atomic.StoreInt64(&c.agentMaxSize, int64(maxSize))
+		}
+	} else {
+		errorStatus, ok := parseErrorStatus(b[:n])
+		if ok && errorStatus == int(gosnmp.TooBig) {
+			atomic.StoreInt32(&c.tooBig, 1)
+		}
+
+		lastRead := make([]byte, n)
+		copy(lastRead, b[:n])
+
+		c.lastReadMutex.Lock()
+		c.lastRead = lastRead
+		c.lastReadMutex.Unlock()
+	}
+
+	return n, addr, err
+}
+
+// getLastRead returns the last datagram read (SNMPv1 / SNMPv2c only)
+func (c *measuringConn) getLastRead() []byte {
+	c.lastReadMutex.Lock()
+	defer c.lastReadMutex.Unlock()
+
+	return c.lastRead
+}
+
+// takeTooBig returns true if a tooBig response has been read since it was last called
+func (c *measuringConn) takeTooBig() bool {
+	return atomic.SwapInt32(&c.tooBig, 0) == 1
+}
+
+func (c *measuringConn) getLastReadSize() int {
+	return int(atomic.LoadInt64(&c.lastReadSize))
+}
+
+// getAgentMaxSize returns the agent's msgMaxSize (0 if it's not known)
+func (c *measuringConn) getAgentMaxSize() int {
+	return int(atomic.LoadInt64(&c.agentMaxSize))
+}
+
+// readBERHeader returns the tag of the TLV at the start of data and where its value starts and ends
+func readBERHeader(data []byte) (tag byte, start int, end int, ok bool) {
+	if len(data) < 2 {
+		return 0, 0, 0, false
+	}
+
+	tag = data[0]
+	length := int(data[1])
+	start = 2
+
+	if length&0x80 != 0 {
+		lengthBytes := length & 0x7f
+		if lengthBytes == 0 || lengthBytes > 4 || len(data) < 2+lengthBytes {
+			return 0, 0, 0, false
+		}
+
+		length = 0
+		for _, b := range data[2 : 2+lengthBytes] {
+			length = length<<8 | int(b)
+		}
+		start += lengthBytes
+	}
+
+	end = start + length
+	if end > len(data) || end < start {
+		return 0, 0, 0, false
+	}
+
+	return tag, start, end, true
+}
+
+// parseMsgMaxSize digs msgMaxSize out of an SNMPv3 message (SEQUENCE { msgVersion, msgGlobalData SEQUENCE { msgID, msgMaxSize, ... } })
+func parseMsgMaxSize(message []byte) (int, bool) {
+	tag, start, end, ok := readBERHeader(message)
+	if !ok || tag != 0x30 {
+		return 0, false
+	}
+	message = message[start:end]
+
+	// msgVersion
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 || end-start != 1 || message[start] != 3 {
+		return 0, false
+	}
+	message = message[end:]
+
+	// msgGlobalData
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag != 0x30 {
+		return 0, false
+	}
+	message = message[start:end]
+
+	// msgID
+	tag, _, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 {
+		return 0, false
+	}
+	message = message[end:]
+
+	// msgMaxSize
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 || end-start < 1 || end-start > 4 {
+		return 0, false
+	}
+
+	maxSize := 0
+	for _, b := range message[start:end] {
+		maxSize = maxSize<<8 | int(b)
+	}
+
+	return maxSize, maxSize > 0
+}
+
+// parseErrorStatus digs the error-status out of an SNMPv1 / SNMPv2c message (SEQUENCE { version, community, PDU { request-id,
+// error-status, ... } })
+func parseErrorStatus(message []byte) (int, bool) {
+	tag, start, end, ok := readBERHeader(message)
+	if !ok || tag != 0x30 {
+		return 0, false
+	}
+	message = message[start:end]
+
+	// version
+	tag, _, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 {
+		return 0, false
+	}
+	message = message[end:]
+
+	// community
+	tag, _, end, ok = readBERHeader(message)
+	if !ok || tag != 0x04 {
+		return 0, false
+	}
+	message = message[end:]
+
+	// PDU (context-specific, constructed)
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag&0xe0 != 0xa0 {
+		return 0, false
+	}
+	message = message[start:end]
+
+	// request-id
+	tag, _, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 {
+		return 0, false
+	}
+	message = message[end:]
+
+	// error-status
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag != 0x02 || end-start != 1 {
+		return 0, false
+	}
+
+	return int(message[start]), true
+}
+
+// estimateHeaderSize estimates how many bytes of a message aren't varbinds (the version, community / security parameters, request
+// ID, error status etc)
+func estimateHeaderSize(version gosnmp.SnmpVersion, community string) int {
+	if version == gosnmp.Version3 {
+		return 128
+	}
+
+	return 32 + len(community)
+}
+
+// getMaxMessageSize returns the most bytes a response should take; max_message_size or the agent's msgMaxSize if that's smaller
+func (w *wrappedSNMP) getMaxMessageSize() int {
+	maxMessageSize := w.maxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultMaxMessageSize
+	}
+
+	if w.conn != nil {
+		agentMaxSize := w.conn.getAgentMaxSize()
+		if agentMaxSize > 0 && agentMaxSize < maxMessageSize {
+			maxMessageSize = agentMaxSize
+		}
+	}
+
+	return maxMessageSize
+}
+
+// recordResponseSize folds the size of a GetBulk response into the average varbind size (measured if we can, estimated if not)
+// and returns the size of the response
+func (w *wrappedSNMP) recordResponseSize(result *gosnmp.SnmpPacket) int {
+	responseSize := 0
+	if w.conn != nil {
+		responseSize = w.conn.getLastReadSize()
+	}
+
+	if responseSize == 0 {
+		responseSize = estimateResponseSize(result)
+	}
+
+	if len(result.Variables) == 0 {
+		return responseSize
+	}
+
+	varbindSize := float64(responseSize-estimateHeaderSize(w.snmp.Version, w.snmp.Community)) / float64(len(result.Variables))
+	if varbindSize < 1 {
+		varbindSize = 1
+	}
+
+	if w.varbindSize == 0 {
+		w.varbindSize = varbindSize
+	} else {
+		w.varbindSize += profileSmoothing * (varbindSize - w.varbindSize)
+	}
+
+	return responseSize
+}
+
+// getFittingMaxRepetitions returns the most repetitions (of a single OID) whose response should fit in getMaxMessageSize going by
+// the size of the varbinds seen so far (no more than we started out with if we've not seen any yet)
+func (w *wrappedSNMP) getFittingMaxRepetitions() uint8 {
+	fitting := math.MaxUint8
+
+	if w.varbindSize > 0 {
+		// allow for the biggest varbinds being bigger than the average
+		varbindSize := w.varbindSize * 1.25
+
+		fitting = int(float64(w.getMaxMessageSize()-estimateHeaderSize(w.snmp.Version, w.snmp.Community)) / varbindSize)
+	} else if w.defaultMaxRepetitions > 0 {
+		fitting = int(w.defaultMaxRepetitions)
+	}
+
+	// and no more than the agent has told us is too big
+	if w.tooBigMaxRepetitions > 0 && fitting >= int(w.tooBigMaxRepetitions) {
+		fitting = int(w.tooBigMaxRepetitions) - 1
+	}
+
+	if fitting < 1 {
+		return 1
+	}
+
+	if fitting > math.MaxUint8 {
+		return math.MaxUint8
+	}
+
+	return uint8(fitting)
+}
@@ -0,0 +1,765 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// smiToken is a token of an SMIv1 / SMIv2 MIB module; quoted strings keep their quotes (so they can't be mistaken for keywords)
+type smiToken struct {
+	text string
+	line int
+}
+
+// tokeniseSMI splits a MIB module into tokens, dropping comments ("--" to the end of the line or the next "--")
+func tokeniseSMI(text string) ([]smiToken, error) {
+	tokens := make([]smiToken, 0)
+	line := 1
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			i += 2
+			for i < len(runes) && runes[i] != '\n' {
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i, line
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("line %v: unterminated string", startLine)
+			}
+			i++
+			tokens = append(tokens, smiToken{string(runes[start:i]), startLine})
+		case c == '\'':
+			// binary / hex string, e.g. '00ff'H
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("line %v: unterminated binary / hex string", line)
+			}
+			i += 2
+			tokens = append(tokens, smiToken{string(runes[start:i]), line})
+		case c == ':' && i+2 < len(runes) && runes[i+1] == ':' && runes[i+2] == '=':
+			tokens = append(tokens, smiToken{"::=", line})
+			i += 3
+		case c == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			tokens = append(tokens, smiToken{"..", line})
+			i += 2
+		case c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]), unicode.IsDigit(c):
+			start := i
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, smiToken{string(runes[start:i]), line})
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '-') {
+				// an identifier can't hold "--" (that's a comment)
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, smiToken{strings.TrimRight(string(runes[start:i]), "-"), line})
+		default:
+			tokens = append(tokens, smiToken{string(c), line})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// mibRange is a range (or single value, if Min and Max are the same) from a SIZE or value constraint
+type mibRange struct {
+	Min int64
+	Max int64
+}
+
+// mibNamedNumber is an enumeration (of an INTEGER) or named bit (of BITS)
+type mibNamedNumber struct {
+	Name   string
+	Number int64
+}
+
+// mibSyntax is a SYNTAX clause or the type of a type assignment / TEXTUAL-CONVENTION
+type mibSyntax struct {
+	Base   string           // INTEGER, OCTET STRING, OBJECT IDENTIFIER, BITS, SEQUENCE, SEQUENCE OF, CHOICE or a type name
+	Values []mibNamedNumber // enumerations / named bits
+	Sizes  []mibRange       // SIZE constraint
+	Ranges []mibRange       // value range constraint
+}
+
+// mibIndex is an object from an INDEX clause
+type mibIndex struct {
+	Name    string
+	Implied bool
+}
+
+// mibOIDElement is an element of an OBJECT IDENTIFIER value; a name (referring to another node), a number or both (e.g. org(3))
+type mibOIDElement struct {
+	Name   string
+	Number int64 // -1 if there's only a name
+}
+
+// mibDefinition is an OID assignment (OBJECT IDENTIFIER or one of the macros, e.g. OBJECT-TYPE) parsed from a module
+type mibDefinition struct {
+	Name     string
+	Kind     string // OBJECT IDENTIFIER, OBJECT-TYPE, MODULE-IDENTITY, NOTIFICATION-TYPE etc
+	Value    []mibOIDElement
+	Syntax   *mibSyntax
+	Access   string
+	Status   string
+	Units    string
+	Index    []mibIndex
+	Augments string
+}
+
+// mibTypeDefinition is a type assignment (or TEXTUAL-CONVENTION) parsed from a module
+type mibTypeDefinition struct {
+	Name        string
+	Syntax      mibSyntax
+	DisplayHint string
+	IsTC        bool
+}
+
+// mibModuleDefinition is everything of interest parsed from a module
+type mibModuleDefinition struct {
+	Name        string
+	Imports     map[string]string // symbol -> the module it's imported from
+	Definitions []mibDefinition
+	Types       []mibTypeDefinition
+}
+
+type smiParser struct {
+	tokens []smiToken
+	pos    int
+}
+
+func (p *smiParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos].text
+}
+
+func (p *smiParser) peekAt(offset int) string {
+	if p.pos+offset >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos+offset].text
+}
+
+func (p *smiParser) next() string {
+	text := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+
+	return text
+}
+
+func (p *smiParser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *smiParser) errorf(format string, args ...interface{}) error {
+	line := 0
+	if p.pos < len(p.tokens) {
+		line = p.tokens[p.pos].line
+	} else if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+
+	return fmt.Errorf("line %v: %v", line, fmt.Sprintf(format, args...))
+}
+
+func (p *smiParser) expect(text string) error {
+	if p.peek() != text {
+		return p.errorf("expected %#v, got %#v", text, p.peek())
+	}
+
+	p.next()
+
+	return nil
+}
+
+// skipBalanced skips a bracketed group (the current token must be the opening bracket)
+func (p *smiParser) skipBalanced() {
+	depth := 0
+	for !p.atEnd() {
+		switch p.next() {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+		}
+
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+func unquoteSMI(text string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(text, "\""), "\"")
+}
+
+func isSMIString(text string) bool {
+	return strings.HasPrefix(text, "\"")
+}
+
+// parseSMINumber parses a number (including 'hex'H and 'binary'B forms, and MIN / MAX); anything too big is taken as the most an
+// int64 can hold (which only matters for the upper bounds of Counter64 and the like)
+func parseSMINumber(text string) (int64, bool) {
+	switch text {
+	case "MIN":
+		return math.MinInt64, true
+	case "MAX":
+		return math.MaxInt64, true
+	}
+
+	if strings.HasPrefix(text, "'") && len(text) >= 3 {
+		digits := text[1 : len(text)-2]
+		base := 16
+		if strings.HasSuffix(strings.ToUpper(text), "'B") {
+			base = 2
+		}
+
+		if digits == "" {
+			return 0, true
+		}
+
+		value, ok := new(big.Int).SetString(digits, base)
+		if !ok || !value.IsInt64() {
+			return 0, false
+		}
+
+		return value.Int64(), true
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		// e.g. the upper bound of Counter64
+		bigValue, ok := new(big.Int).SetString(text, 10)
+		if ok && bigValue.Sign() > 0 {
+			return math.MaxInt64, true
+		}
+
+		return 0, false
+	}
+
+	return value, true
+}
+
+// parseSMI parses the MIB modules in a file (there's usually just the one)
+func parseSMI(text string) ([]*mibModuleDefinition, error) {
+	tokens, err := tokeniseSMI(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &smiParser{tokens: tokens}
+
+	modules := make([]*mibModuleDefinition, 0)
+	for !p.atEnd() {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+
+		modules = append(modules, module)
+	}
+
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no module found")
+	}
+
+	return modules, nil
+}
+
+func (p *smiParser) parseModule() (*mibModuleDefinition, error) {
+	module := &mibModuleDefinition{
+		Name:        p.next(),
+		Imports:     make(map[string]string),
+		Definitions: make([]mibDefinition, 0),
+		Types:       make([]mibTypeDefinition, 0),
+	}
+
+	// e.g. "IF-MIB { iso ... } DEFINITIONS" (SMIv1 modules sometimes carry their OID)
+	if p.peek() == "{" {
+		p.skipBalanced()
+	}
+
+	err := p.expect("DEFINITIONS")
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.expect("::=")
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.expect("BEGIN")
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.atEnd() && p.peek() != "END" {
+		err = p.parseStatement(module)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", module.Name, err)
+		}
+	}
+
+	err = p.expect("END")
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", module.Name, err)
+	}
+
+	return module, nil
+}
+
+func (p *smiParser) parseStatement(module *mibModuleDefinition) error {
+	name := p.next()
+
+	switch name {
+	case "IMPORTS":
+		return p.parseImports(module)
+	case "EXPORTS":
+		for !p.atEnd() && p.next() != ";" {
+		}
+
+		return nil
+	case ";", ",":
+		return nil
+	}
+
+	switch p.peek() {
+	case "MACRO":
+		// macro definitions (e.g. in SNMPv2-SMI) only describe the notation
+		for !p.atEnd() && p.next() != "END" {
+		}
+
+		return nil
+	case "::=":
+		p.next()
+
+		return p.parseTypeAssignment(module, name)
+	case "OBJECT":
+		if p.peekAt(1) == "IDENTIFIER" {
+			p.pos += 2
+
+			err := p.expect("::=")
+			if err != nil {
+				return err
+			}
+
+			value, err := p.parseOIDValue()
+			if err != nil {
+				return err
+			}
+
+			module.Definitions = append(module.Definitions, mibDefinition{Name: name, Kind: "OBJECT IDENTIFIER", Value: value})
+
+			return nil
+		}
+	}
+
+	// a macro invocation, e.g. ifInOctets OBJECT-TYPE SYNTAX Counter32 ... ::= { ifEntry 10 }
+	definition := mibDefinition{
+		Name: name,
+		Kind: p.next(),
+	}
+
+	err := p.parseClauses(&definition.Syntax, &definition)
+	if err != nil {
+		return err
+	}
+
+	err = p.expect("::=")
+	if err != nil {
+		return err
+	}
+
+	// TRAP-TYPE (SMIv1) is given a number rather than an OID
+	if p.peek() != "{" {
+		p.next()
+
+		return nil
+	}
+
+	definition.Value, err = p.parseOIDValue()
+	if err != nil {
+		return err
+	}
+
+	module.Definitions = append(module.Definitions, definition)
+
+	return nil
+}
+
+func (p *smiParser) parseImports(module *mibModuleDefinition) error {
+	symbols := make([]string, 0)
+
+	for !p.atEnd() {
+		token := p.next()
+
+		switch token {
+		case ";":
+			return nil
+		case ",":
+			continue
+		case "FROM":
+			from := p.next()
+			for _, symbol := range symbols {
+				module.Imports[symbol] = from
+			}
+			symbols = make([]string, 0)
+		default:
+			symbols = append(symbols, token)
+		}
+	}
+
+	return p.errorf("unterminated IMPORTS")
+}
+
+// parseClauses parses the clauses of a macro invocation (or TEXTUAL-CONVENTION) up to the "::=" (or the end of the SYNTAX clause
+// of a TEXTUAL-CONVENTION, if definition is nil)
+func (p *smiParser) parseClauses(syntax **mibSyntax, definition *mibDefinition) error {
+	for !p.atEnd() && p.peek() != "::=" && p.peek() != "END" {
+		token := p.next()
+
+		switch token {
+		case "SYNTAX":
+			parsedSyntax, err := p.parseType()
+			if err != nil {
+				return err
+			}
+
+			// the SYNTAX of a MODULE-COMPLIANCE's OBJECT refinement isn't the node's
+			if *syntax == nil {
+				*syntax = &parsedSyntax
+			}
+
+			if definition == nil {
+				return nil
+			}
+		case "INDEX":
+			if definition == nil || p.peek() != "{" {
+				continue
+			}
+			p.next()
+
+			implied := false
+			for !p.atEnd() {
+				token := p.next()
+				if token == "}" {
+					break
+				}
+
+				switch token {
+				case ",":
+				case "IMPLIED":
+					implied = true
+				default:
+					definition.Index = append(definition.Index, mibIndex{Name: token, Implied: implied})
+					implied = false
+				}
+			}
+		case "AUGMENTS":
+			if definition == nil || p.peek() != "{" {
+				continue
+			}
+			p.next()
+
+			definition.Augments = p.next()
+			for !p.atEnd() && p.next() != "}" {
+			}
+		case "ACCESS", "MAX-ACCESS":
+			if definition != nil && definition.Access == "" {
+				definition.Access = p.next()
+			}
+		case "STATUS":
+			if definition != nil && definition.Status == "" {
+				definition.Status = p.next()
+			}
+		case "UNITS":
+			if definition != nil && isSMIString(p.peek()) {
+				definition.Units = unquoteSMI(p.next())
+			}
+		case "{", "(", "[":
+			p.pos--
+			p.skipBalanced()
+		}
+	}
+
+	return nil
+}
+
+// parseTypeAssignment parses what follows "Name ::=" (a TEXTUAL-CONVENTION or a type)
+func (p *smiParser) parseTypeAssignment(module *mibModuleDefinition, name string) error {
+	typeDefinition := mibTypeDefinition{
+		Name: name,
+	}
+
+	if p.peek() == "TEXTUAL-CONVENTION" {
+		p.next()
+
+		typeDefinition.IsTC = true
+
+		// the DISPLAY-HINT comes before the SYNTAX (which ends the TEXTUAL-CONVENTION)
+		for !p.atEnd() && p.peek() != "SYNTAX" {
+			token := p.next()
+			if token == "DISPLAY-HINT" && isSMIString(p.peek()) {
+				typeDefinition.DisplayHint = unquoteSMI(p.next())
+			}
+		}
+
+		var syntax *mibSyntax
+		err := p.parseClauses(&syntax, nil)
+		if err != nil {
+			return err
+		}
+
+		if syntax == nil {
+			return p.errorf("TEXTUAL-CONVENTION %v has no SYNTAX", name)
+		}
+
+		typeDefinition.Syntax = *syntax
+	} else {
+		syntax, err := p.parseType()
+		if err != nil {
+			return err
+		}
+
+		typeDefinition.Syntax = syntax
+	}
+
+	module.Types = append(module.Types, typeDefinition)
+
+	return nil
+}
+
+// parseType parses a type, e.g. INTEGER { up(1), down(2) }, OCTET STRING (SIZE (0..255)) or [APPLICATION 1] IMPLICIT INTEGER
+func (p *smiParser) parseType() (mibSyntax, error) {
+	syntax := mibSyntax{}
+
+	// a tag (as per the base types in SNMPv2-SMI / RFC1155-SMI)
+	if p.peek() == "[" {
+		p.skipBalanced()
+		if p.peek() == "IMPLICIT" || p.peek() == "EXPLICIT" {
+			p.next()
+		}
+	}
+
+	switch token := p.next(); token {
+	case "":
+		return syntax, p.errorf("expected a type")
+	case "OCTET", "OBJECT":
+		second := p.next()
+		syntax.Base = token + " " + second
+	case "SEQUENCE":
+		if p.peek() == "OF" {
+			p.next()
+			syntax.Base = "SEQUENCE OF"
+			p.next()
+			return syntax, nil
+		}
+
+		syntax.Base = "SEQUENCE"
+		if p.peek() == "{" {
+			p.skipBalanced()
+		}
+
+		return syntax, nil
+	case "CHOICE":
+		syntax.Base = "CHOICE"
+		if p.peek() == "{" {
+			p.skipBalanced()
+		}
+
+		return syntax, nil
+	default:
+		syntax.Base = token
+	}
+
+	// enumerations / named bits
+	if p.peek() == "{" {
+		p.next()
+
+		for !p.atEnd() {
+			token := p.next()
+			if token == "}" {
+				break
+			}
+
+			if token == "," {
+				continue
+			}
+
+			if p.peek() != "(" {
+				continue
+			}
+			p.next()
+
+			number, ok := parseSMINumber(p.next())
+			if !ok {
+				return syntax, p.errorf("invalid number for %v", token)
+			}
+
+			err := p.expect(")")
+			if err != nil {
+				return syntax, err
+			}
+
+			syntax.Values = append(syntax.Values, mibNamedNumber{Name: token, Number: number})
+		}
+	}
+
+	// constraints
+	if p.peek() == "(" {
+		p.next()
+
+		if p.peek() == "SIZE" {
+			p.next()
+
+			err := p.expect("(")
+			if err != nil {
+				return syntax, err
+			}
+
+			syntax.Sizes, err = p.parseRanges()
+			if err != nil {
+				return syntax, err
+			}
+
+			err = p.expect(")")
+			if err != nil {
+				return syntax, err
+			}
+		} else {
+			var err error
+
+			syntax.Ranges, err = p.parseRanges()
+			if err != nil {
+				return syntax, err
+			}
+		}
+
+		err := p.expect(")")
+		if err != nil {
+			return syntax, err
+		}
+	}
+
+	return syntax, nil
+}
+
+// parseRanges parses e.g. 0..255 | 1024..65535, up to (but not including) the closing bracket
+func (p *smiParser) parseRanges() ([]mibRange, error) {
+	ranges := make([]mibRange, 0)
+
+	for !p.atEnd() && p.peek() != ")" {
+		if p.peek() == "|" {
+			p.next()
+			continue
+		}
+
+		minimum, ok := parseSMINumber(p.next())
+		if !ok {
+			return nil, p.errorf("invalid range")
+		}
+
+		maximum := minimum
+		if p.peek() == ".." {
+			p.next()
+
+			maximum, ok = parseSMINumber(p.next())
+			if !ok {
+				return nil, p.errorf("invalid range")
+			}
+		}
+
+		ranges = append(ranges, mibRange{Min: minimum, Max: maximum})
+	}
+
+	return ranges, nil
+}
+
+// parseOIDValue parses e.g. { ifEntry 10 } or { iso org(3) dod(6) 1 }
+func (p *smiParser) parseOIDValue() ([]mibOIDElement, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]mibOIDElement, 0)
+
+	for !p.atEnd() {
+		token := p.next()
+		if token == "}" {
+			break
+		}
+
+		number, ok := parseSMINumber(token)
+		if ok {
+			elements = append(elements, mibOIDElement{Number: number})
+			continue
+		}
+
+		element := mibOIDElement{Name: token, Number: -1}
+
+		if p.peek() == "(" {
+			p.next()
+
+			element.Number, ok = parseSMINumber(p.next())
+			if !ok {
+				return nil, p.errorf("invalid number for %v", token)
+			}
+
+			err = p.expect(")")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		elements = append(elements, element)
+	}
+
+	if len(elements) == 0 {
+		return nil, p.errorf("empty OBJECT IDENTIFIER value")
+	}
+
+	return elements, nil
+}
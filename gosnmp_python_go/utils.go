@@ -13,10 +13,15 @@ import (
 	"strings"
 )
 
-// releaseGIL releases (unlocks) the Python GIL
+// releaseGIL releases (unlocks) the Python GIL; a no-op if there's no interpreter (i.e. the RPCs are being called from Go, as in the
+// tests)
 func releaseGIL() *C.PyThreadState {
 	var tState *C.PyThreadState
 
+	if C.Py_IsInitialized() == 0 {
+		return nil
+	}
+
 	tState = C.PyEval_SaveThread()
 
 	return tState
@@ -31,20 +36,34 @@ func reacquireGIL(tState *C.PyThreadState) {
 	C.PyEval_RestoreThread(tState)
 }
 
-// formatOID ensures an OID string is in the right format
-func formatOID(oid string) string {
+// formatOID ensures an OID string is in the right format; names (e.g. IF-MIB::ifInOctets.3) are resolved, which fails if their MIBs
+// aren't loaded (see loadMIBs)
+func formatOID(oid string) (string, error) {
+	if !isNumericOID(oid) {
+		return resolveOIDName(oid)
+	}
+
+	return formatNumericOID(oid), nil
+}
+
+func formatNumericOID(oid string) string {
 	return fmt.Sprintf(".%v", strings.Trim(oid, "."))
 }
 
 // formatOIDs ensures each OID in a slice is in the right format
-func formatOIDs(oids []string) []string {
+func formatOIDs(oids []string) ([]string, error) {
 	formattedOIDs := make([]string, 0)
 
 	for _, oid := range oids {
-		formattedOIDs = append(formattedOIDs, formatOID(oid))
+		formattedOID, err := formatOID(oid)
+		if err != nil {
+			return nil, err
+		}
+
+		formattedOIDs = append(formattedOIDs, formattedOID)
 	}
 
-	return formattedOIDs
+	return formattedOIDs, nil
 }
 
 // splitOID splits an OID string into a slice
@@ -69,6 +88,48 @@ func oidToFloat(oid string) (float64, error) {
 	return oidFloat, nil
 }
 
+// compareOIDs compares two OIDs arc by arc, returning -1 if a comes before b, 1 if a comes after b and 0 if they're the same
+func compareOIDs(a, b string) int {
+	aParts := splitOID(a)
+	bParts := splitOID(b)
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aPart, aErr := strconv.ParseUint(aParts[i], 10, 64)
+		bPart, bErr := strconv.ParseUint(bParts[i], 10, 64)
+
+		// shouldn't happen, but fall back to comparing as strings
+		if aErr != nil || bErr != nil {
+			if aParts[i] != bParts[i] {
+				if aParts[i] < bParts[i] {
+					return -1
+				}
+
+				return 1
+			}
+
+			continue
+		}
+
+		if aPart != bPart {
+			if aPart < bPart {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	if len(aParts) < len(bParts) {
+		return -1
+	}
+
+	if len(aParts) > len(bParts) {
+		return 1
+	}
+
+	return 0
+}
+
 // getVariableByName returns variables by their OID for comparison, sorting etc
 func getVariableByName(result *gosnmp.SnmpPacket) map[string]gosnmp.SnmpPDU {
 	variablesByName := make(map[string]gosnmp.SnmpPDU, 0)
@@ -146,7 +207,8 @@ func deduplicateResult(result *gosnmp.SnmpPacket) {
 	sortVariables(result)
 }
 
-// isThisAnEndVariable returns true if this is EndOfMibView, etc
+// isThisAnEndVariable returns true if this is EndOfMibView, etc; not EndOfContents, as that's also UnknownType (what gosnmp gives for
+// a value it can't decode, see recoverUnknownValues) and one odd value shouldn't end a walk
 func isThisAnEndVariable(pdu gosnmp.SnmpPDU) bool {
 	switch pdu.Type {
 	case gosnmp.NoSuchInstance:
@@ -154,8 +216,6 @@ func isThisAnEndVariable(pdu gosnmp.SnmpPDU) bool {
 	case gosnmp.NoSuchObject:
 		fallthrough
 	case gosnmp.EndOfMibView:
-		fallthrough
-	case gosnmp.EndOfContents:
 		return true
 	}
 
@@ -174,3 +234,85 @@ func hasOIDPrefix(oid string, oidPrefix string) bool {
 
 	return match
 }
+
+// berLengthSize is how many bytes BER takes to encode a length
+func berLengthSize(length int) int {
+	if length < 128 {
+		return 1
+	}
+
+	size := 1
+	for length > 0 {
+		size++
+		length >>= 8
+	}
+
+	return size
+}
+
+// berTLVSize is how many bytes BER takes to encode a tag, length and a value of the given length
+func berTLVSize(length int) int {
+	return 1 + berLengthSize(length) + length
+}
+
+// berOIDSize is how many bytes BER takes to encode an OID's value (the first two arcs share a byte, the rest are base 128)
+func berOIDSize(oid string) int {
+	parts := splitOID(oid)
+
+	size := 1
+	for i, part := range parts {
+		if i < 2 {
+			continue
+		}
+
+		arc, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			arc = 0
+		}
+
+		size++
+		for arc >= 128 {
+			size++
+			arc >>= 7
+		}
+	}
+
+	return size
+}
+
+// estimateVarbindSize estimates how many bytes a varbind takes on the wire
+func estimateVarbindSize(pdu gosnmp.SnmpPDU) int {
+	valueSize := 0
+
+	switch pdu.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32, gosnmp.Counter64:
+		// BER integers are two's complement, so e.g. 255 needs a leading zero
+		valueSize = gosnmp.ToBigInt(pdu.Value).BitLen()/8 + 1
+	case gosnmp.OctetString, gosnmp.Opaque, gosnmp.BitString:
+		switch value := pdu.Value.(type) {
+		case []byte:
+			valueSize = len(value)
+		case string:
+			valueSize = len(value)
+		}
+	case gosnmp.ObjectIdentifier:
+		if value, ok := pdu.Value.(string); ok {
+			valueSize = berOIDSize(value)
+		}
+	case gosnmp.IPAddress:
+		valueSize = 4
+	}
+
+	return berTLVSize(berTLVSize(berOIDSize(pdu.Name)) + berTLVSize(valueSize))
+}
+
+// estimateResponseSize estimates how many bytes a response takes on the wire (see estimateHeaderSize)
+func estimateResponseSize(result *gosnmp.SnmpPacket) int {
+	size := estimateHeaderSize(result.Version, result.Community)
+
+	for _, variable := range result.Variables {
+		size += estimateVarbindSize(variable)
+	}
+
+	return size
+}
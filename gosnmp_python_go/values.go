@@ -0,0 +1,190 @@
+package gosnmp_python_go
+
+import (
+	"bytes"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// rawValue is a value of a type we don't know what to do with, as it came in the message
+type rawValue struct {
+	tag  byte
+	data []byte
+}
+
+// rawVarbind is the value of a varbind as it came in the message (see parseRawVarbinds)
+type rawVarbind struct {
+	tag  byte
+	data []byte // aliases the message
+	ok   bool   // false if it has a tag we can't read (i.e. a multi-byte one)
+}
+
+// parseRawVarbinds digs the values of the varbinds out of an SNMPv1 / SNMPv2c message (SEQUENCE { version, community, PDU {
+// request-id, error-status, error-index, SEQUENCE { SEQUENCE { name, value }, ... } } })
+func parseRawVarbinds(message []byte) ([]rawVarbind, bool) {
+	tag, start, end, ok := readBERHeader(message)
+	if !ok || tag != 0x30 {
+		return nil, false
+	}
+	message = message[start:end]
+
+	// version, community
+	for _, expected := range []byte{0x02, 0x04} {
+		tag, _, end, ok = readBERHeader(message)
+		if !ok || tag != expected {
+			return nil, false
+		}
+		message = message[end:]
+	}
+
+	// PDU (context-specific, constructed)
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag&0xe0 != 0xa0 {
+		return nil, false
+	}
+	message = message[start:end]
+
+	// request-id, error-status, error-index
+	for i := 0; i < 3; i++ {
+		tag, _, end, ok = readBERHeader(message)
+		if !ok || tag != 0x02 {
+			return nil, false
+		}
+		message = message[end:]
+	}
+
+	// variable-bindings
+	tag, start, end, ok = readBERHeader(message)
+	if !ok || tag != 0x30 {
+		return nil, false
+	}
+	message = message[start:end]
+
+	varbinds := make([]rawVarbind, 0)
+	for len(message) > 0 {
+		tag, start, end, ok = readBERHeader(message)
+		if !ok || tag != 0x30 {
+			return nil, false
+		}
+		varbind := message[start:end]
+		message = message[end:]
+
+		// name
+		tag, _, end, ok = readBERHeader(varbind)
+		if !ok || tag != 0x06 {
+			return nil, false
+		}
+		varbind = varbind[end:]
+
+		// value
+		if len(varbind) > 0 && varbind[0]&0x1f == 0x1f {
+			varbinds = append(varbinds, rawVarbind{})
+			continue
+		}
+
+		tag, start, end, ok = readBERHeader(varbind)
+		if !ok {
+			return nil, false
+		}
+
+		varbinds = append(varbinds, rawVarbind{tag: tag, data: varbind[start:end], ok: true})
+	}
+
+	return varbinds, true
+}
+
+func parseRawUint(data []byte, size int) (uint64, bool) {
+	// a leading zero octet keeps the top bit clear
+	if len(data) == size+1 && data[0] == 0 {
+		data = data[1:]
+	}
+
+	if len(data) < 1 || len(data) > size {
+		return 0, false
+	}
+
+	value := uint64(0)
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+
+	return value, true
+}
+
+// decodeRawValue decodes a value that gosnmp couldn't (it has no case for some types, and gives up on others that don't parse)
+func decodeRawValue(tag byte, data []byte) (gosnmp.Asn1BER, interface{}) {
+	valueType := gosnmp.Asn1BER(tag)
+
+	switch valueType {
+	case gosnmp.Boolean:
+		if len(data) == 1 {
+			return valueType, data[0] != 0
+		}
+
+	case gosnmp.BitString:
+		// the first octet is how many bits of the last octet are unused
+		if len(data) >= 1 {
+			return valueType, append([]byte{}, data[1:]...)
+		}
+
+	case gosnmp.NsapAddress, gosnmp.Opaque:
+		return valueType, append([]byte{}, data...)
+
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		value, ok := parseRawUint(data, 4)
+		if ok {
+			return valueType, uint(value)
+		}
+
+	case gosnmp.Counter64:
+		value, ok := parseRawUint(data, 8)
+		if ok {
+			return valueType, value
+		}
+	}
+
+	return gosnmp.UnknownType, rawValue{tag: tag, data: append([]byte{}, data...)}
+}
+
+// recoverUnknownValues replaces the values gosnmp couldn't decode (it gives them as UnknownType, with no value) with what they are,
+// from the message they came in; likewise any Opaque that isn't a float or a double, as gosnmp decodes the contents of an Opaque as if
+// they were a value of their own (so they come out as some other type, or UnknownType). SNMPv1 / SNMPv2c only, as SNMPv3 messages may
+// be encrypted
+func recoverUnknownValues(result *gosnmp.SnmpPacket, message []byte) {
+	if result == nil || len(message) == 0 {
+		return
+	}
+
+	// an Opaque can only be told by its tag in the message (and a message without that byte anywhere can't hold one)
+	recoverable := bytes.IndexByte(message, byte(gosnmp.Opaque)) >= 0
+	for _, variable := range result.Variables {
+		if variable.Type == gosnmp.UnknownType {
+			recoverable = true
+			break
+		}
+	}
+
+	if !recoverable {
+		return
+	}
+
+	varbinds, ok := parseRawVarbinds(message)
+	if !ok || len(varbinds) != len(result.Variables) {
+		return
+	}
+
+	for i, varbind := range varbinds {
+		if !varbind.ok {
+			continue
+		}
+
+		variable := &result.Variables[i]
+
+		isOpaque := varbind.tag == byte(gosnmp.Opaque) && variable.Type != gosnmp.OpaqueFloat && variable.Type != gosnmp.OpaqueDouble
+		if variable.Type != gosnmp.UnknownType && !isOpaque {
+			continue
+		}
+
+		variable.Type, variable.Value = decodeRawValue(varbind.tag, varbind.data)
+	}
+}
@@ -0,0 +1,58 @@
+package gosnmp_python_go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecoverUnknownValues(t *testing.T) {
+	const base = ".1.3.6.1.4.1.99999.9"
+
+	a := newTestAgent(t, []testVarbind{
+		{oid: base + ".1", tag: 0x44, data: []byte{0x04, 0x02, 'h', 'i'}},               // an Opaque that looks like an OCTET STRING
+		{oid: base + ".2", tag: 0x44, data: []byte{0x02, 0x01, 0x05}},                   // an Opaque that looks like an INTEGER
+		{oid: base + ".3", tag: 0x44, data: []byte{0xde, 0xad}},                         // an Opaque that looks like nothing
+		{oid: base + ".4", tag: 0x44, data: []byte{0x9f, 0x78, 0x04, 0x3f, 0xc0, 0, 0}}, // an Opaque float (1.5)
+		{oid: base + ".5", tag: 0x4f, data: []byte{0x01, 0x02}},                         // a type nobody knows
+		testOctetString(base+".6", "DD"),
+	})
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	s, _ := getSession(sessionID)
+
+	expected := []multiResult{
+		{OID: base + ".1", Type: "bytearray", ByteArrayValue: []int{0x04, 0x02, 'h', 'i'}},
+		{OID: base + ".2", Type: "bytearray", ByteArrayValue: []int{0x02, 0x01, 0x05}},
+		{OID: base + ".3", Type: "bytearray", ByteArrayValue: []int{0xde, 0xad}},
+		{OID: base + ".4", Type: "float", FloatValue: 1.5},
+		{OID: base + ".5", Type: "unknown", IsUnknown: true, IntValue: 0x4f, ByteArrayValue: []int{0x01, 0x02}},
+		{OID: base + ".6", Type: "bytearray", ByteArrayValue: []int{'D', 'D'}},
+	}
+
+	walks := map[string]func(string, walkOptions) ([]multiResult, error){"walk": s.walk, "walkBulk": s.walkBulk}
+
+	for way, walk := range walks {
+		multiResults, err := walk(base, walkOptions{})
+		if err != nil {
+			t.Fatalf("%v: failed to walk: %v", way, err)
+		}
+
+		if len(multiResults) != len(expected) {
+			t.Fatalf("%v: expected %v results, got %+v", way, len(expected), multiResults)
+		}
+
+		for i, multiResult := range multiResults {
+			got := multiResult
+			got.valueType = 0
+
+			if !reflect.DeepEqual(got, expected[i]) {
+				t.Errorf("%v: expected %+v, got %+v", way, expected[i], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package gosnmp_python_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ftpsolutions/gosnmp"
+)
+
+// walkOptions narrow down what a walk returns; the zero value walks the whole tree
+type walkOptions struct {
+	StopOID     string   `json:"stop_oid"`     // stop on reaching this OID (it's not included); empty for the end of the tree
+	MaxVarbinds int      `json:"max_varbinds"` // stop once this many varbinds have been gathered; 0 for no limit
+	Exclude     []string `json:"exclude"`      // prefixes of subtrees to skip over (without fetching them where possible)
+	ResumeOID   string   `json:"resume_oid"`   // carry on a walk after this OID (e.g. the LastOID of a partial walk)
+	Partial     bool     `json:"partial"`      // return what was gathered (as a partialWalkResult) if the walk fails part way
+	// carry on past OIDs that don't come after the one asked for (as per net-snmp's -Cc) rather than failing; an OID coming
+	// back a second time still fails (as the walk would otherwise go round in circles)
+	AllowNonIncreasing bool `json:"allow_non_increasing"`
+}
+
+// nonIncreasingOIDError is returned by a walk when the agent answers with an OID that comes before the one asked for (or, with
+// AllowNonIncreasing, one that it's already returned); the OID asked for coming back is taken as the end of the walk instead
+type nonIncreasingOIDError struct {
+	Previous string
+	Next     string
+	Loop     bool
+}
+
+func (e nonIncreasingOIDError) Error() string {
+	if e.Loop {
+		return fmt.Sprintf("OID loop detected: %v returned again (after %v)", e.Next, e.Previous)
+	}
+
+	return fmt.Sprintf("OID not increasing: %v followed %v", e.Next, e.Previous)
+}
+
+// oidOrderChecker keeps track of the OIDs a walk has been given to spot the agent going backwards (or round in circles)
+type oidOrderChecker struct {
+	allowNonIncreasing bool
+	seen               map[string]bool // only needed (and so only kept) if allowNonIncreasing
+}
+
+func newOIDOrderChecker(options walkOptions) *oidOrderChecker {
+	return &oidOrderChecker{
+		allowNonIncreasing: options.AllowNonIncreasing,
+		seen:               make(map[string]bool),
+	}
+}
+
+// check returns a nonIncreasingOIDError if next doesn't come after previous (unless that's allowed and next is new to the walk)
+func (c *oidOrderChecker) check(previous string, next string) error {
+	if c.allowNonIncreasing {
+		if c.seen[next] {
+			return nonIncreasingOIDError{Previous: previous, Next: next, Loop: true}
+		}
+
+		c.seen[next] = true
+
+		return nil
+	}
+
+	if compareOIDs(next, previous) <= 0 {
+		return nonIncreasingOIDError{Previous: previous, Next: next}
+	}
+
+	return nil
+}
+
+// partialWalkResult is what a walk with the partial option returns; LastOID is where to resume from if there's an Error
+type partialWalkResult struct {
+	Results []multiResult
+	Error   string
+	LastOID string
+}
+
+func parseWalkOptions(optionsJSON string) (walkOptions, error) {
+	options := walkOptions{}
+
+	err := json.Unmarshal([]byte(optionsJSON), &options)
+	if err != nil {
+		return walkOptions{}, fmt.Errorf("failed to parse walk options: %v", err)
+	}
+
+	err = options.validate()
+	if err != nil {
+		return walkOptions{}, err
+	}
+
+	return options, nil
+}
+
+// validate normalises the options in-place and returns an error describing the first problem found
+func (o *walkOptions) validate() error {
+	var err error
+
+	if o.StopOID != "" {
+		o.StopOID, err = formatOID(o.StopOID)
+		if err != nil {
+			return fmt.Errorf("stop_oid is invalid: %v", err)
+		}
+	}
+
+	if o.MaxVarbinds < 0 {
+		return fmt.Errorf("max_varbinds %v is invalid; must not be negative", o.MaxVarbinds)
+	}
+
+	o.Exclude, err = formatOIDs(o.Exclude)
+	if err != nil {
+		return fmt.Errorf("exclude is invalid: %v", err)
+	}
+
+	if o.ResumeOID != "" {
+		o.ResumeOID, err = formatOID(o.ResumeOID)
+		if err != nil {
+			return fmt.Errorf("resume_oid is invalid: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// isPastStop returns true if the walk should stop at this OID
+func (o *walkOptions) isPastStop(oid string) bool {
+	return o.StopOID != "" && compareOIDs(oid, o.StopOID) >= 0
+}
+
+// isFull returns true if the walk has gathered as many varbinds as it may
+func (o *walkOptions) isFull(count int) bool {
+	return o.MaxVarbinds > 0 && count >= o.MaxVarbinds
+}
+
+// getSkipOID returns the OID to continue walking from if oid is in an excluded subtree (the last possible OID in that subtree,
+// so the next GetNext / GetBulk leaves it) and false otherwise
+func (o *walkOptions) getSkipOID(oid string) (string, bool) {
+	for _, prefix := range o.Exclude {
+		if !hasOIDPrefix(oid, prefix) {
+			continue
+		}
+
+		skipOID := fmt.Sprintf("%v.%v", prefix, strconv.FormatUint(math.MaxUint32, 10))
+
+		// a (pathological) OID past our skip OID that's still in the subtree- just keep going from it
+		if compareOIDs(oid, skipOID) > 0 {
+			return oid, true
+		}
+
+		return skipOID, true
+	}
+
+	return "", false
+}
+
+// walkMany walks all of the oids at once, with a varbind for each that's still going in every GetBulk (so the responses hold a
+// row's worth of columns at a time); it returns a result for each oid (in the same order)
+func (w *wrappedSNMP) walkMany(oids []string, options walkOptions) ([]*gosnmp.SnmpPacket, error) {
+	oids, err := formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(oids) == 0 {
+		return nil, fmt.Errorf("oids must be length of 1 or more")
+	}
+
+	if options.ResumeOID != "" || options.Partial {
+		return nil, fmt.Errorf("resume_oid and partial aren't supported when walking several trees at once")
+	}
+
+	results := make([]*gosnmp.SnmpPacket, len(oids))
+	for i := range results {
+		results[i] = &gosnmp.SnmpPacket{
+			Variables: make([]gosnmp.SnmpPDU, 0),
+		}
+	}
+
+	// no GetBulk for SNMPv1 (unless it's being emulated)- walk one after the other
+	if !w.canGetBulk() {
+		for i, oid := range oids {
+			result, err := w.specialWalk(oid, oid, options)
+			if err != nil {
+				return nil, err
+			}
+
+			results[i] = result
+		}
+
+		return results, nil
+	}
+
+	cursors := make([]string, len(oids))
+	copy(cursors, oids)
+
+	orderCheckers := make([]*oidOrderChecker, len(oids))
+	for i := range orderCheckers {
+		orderCheckers[i] = newOIDOrderChecker(options)
+	}
+
+	active := make([]int, 0)
+	for i := range oids {
+		active = append(active, i)
+	}
+
+	maxRepetitionsLimit := math.MaxUint8
+	failures := 0
+
+	for len(active) > 0 {
+		batch := active
+		if w.snmp.MaxOids > 0 && len(batch) > w.snmp.MaxOids {
+			batch = active[:w.snmp.MaxOids]
+		}
+
+		// keep the size of each response about what the device is used to (optimalMaxRepetitions for a single walk) and no more than
+		// will fit (see getFittingMaxRepetitions)
+		optimalMaxRepetitions := w.optimalMaxRepetitions
+		if fittingMaxRepetitions := w.getFittingMaxRepetitions(); optimalMaxRepetitions > fittingMaxRepetitions {
+			optimalMaxRepetitions = fittingMaxRepetitions
+		}
+
+		maxRepetitions := int(optimalMaxRepetitions) / len(batch)
+		if maxRepetitions > maxRepetitionsLimit {
+			maxRepetitions = maxRepetitionsLimit
+		}
+		if maxRepetitions < 1 {
+			maxRepetitions = 1
+		}
+
+		batchOIDs := make([]string, 0)
+		for _, i := range batch {
+			batchOIDs = append(batchOIDs, cursors[i])
+		}
+
+		thisResult, err := w.getBulk(batchOIDs, 0, uint8(maxRepetitions))
+		if err == nil && thisResult != nil && thisResult.Error == gosnmp.TooBig {
+			err = fmt.Errorf("tooBig for GetBulk oids=%v, nonRepeaters=0, maxRepetitions=%v", batchOIDs, maxRepetitions)
+		}
+
+		if err != nil {
+			if maxRepetitions <= 1 {
+				return nil, err
+			}
+
+			// ask for less next time and give a struggling device some breathing room (as per the retryPolicy)
+			maxRepetitionsLimit = maxRepetitions / 2
+			failures++
+			time.Sleep(w.retryPolicy.getDelay(failures))
+
+			continue
+		}
+
+		failures = 0
+
+		if thisResult == nil || len(thisResult.Variables) == 0 {
+			return nil, fmt.Errorf("nothing returned for GetBulk oids=%v, nonRepeaters=0, maxRepetitions=%v", batchOIDs, maxRepetitions)
+		}
+
+		// the varbinds come back a row at a time (a varbind for each of the requested OIDs, then the next for each etc)
+		stillActive := make([]int, 0)
+		for j, i := range batch {
+			finished := false
+
+			// the last OID we were given, which the cursor (what's asked for next) is past if it's been excluded
+			previousOID := cursors[i]
+
+			for k := j; k < len(thisResult.Variables); k += len(batch) {
+				variable := thisResult.Variables[k]
+
+				// out of our tree or the end (the same OID back is as good as the end)
+				if isThisAnEndVariable(variable) || !hasOIDPrefix(variable.Name, oids[i]) || variable.Name == previousOID {
+					finished = true
+					break
+				}
+
+				// the agent has gone backwards (or round in circles)
+				err = orderCheckers[i].check(previousOID, variable.Name)
+				if err != nil {
+					return nil, err
+				}
+
+				if options.isPastStop(variable.Name) {
+					finished = true
+					break
+				}
+
+				previousOID = variable.Name
+				cursors[i] = variable.Name
+
+				skipOID, skip := options.getSkipOID(variable.Name)
+				if skip {
+					cursors[i] = skipOID
+					continue
+				}
+
+				results[i].Variables = append(results[i].Variables, variable)
+
+				if options.isFull(len(results[i].Variables)) {
+					finished = true
+					break
+				}
+			}
+
+			if !finished {
+				stillActive = append(stillActive, i)
+			}
+		}
+
+		active = append(stillActive, active[len(batch):]...)
+	}
+
+	for _, result := range results {
+		deduplicateResult(result)
+	}
+
+	return results, nil
+}
+
+// getLastOID returns the OID a failed walk got to (to resume from); the last of the results if there are any
+func getLastOID(multiResults []multiResult, options walkOptions) string {
+	if len(multiResults) > 0 {
+		return multiResults[len(multiResults)-1].OID
+	}
+
+	return options.ResumeOID
+}
+
+// buildPartialWalkResults returns what a failed walk got along with the error if the partial option is set (just the error otherwise)
+func buildPartialWalkResults(oid string, result *gosnmp.SnmpPacket, options walkOptions, err error) ([]multiResult, error) {
+	emptyMultiResults := make([]multiResult, 0)
+
+	if !options.Partial || result == nil {
+		return emptyMultiResults, err
+	}
+
+	multiResults, buildErr := buildMultiResults(oid, result)
+	if buildErr != nil {
+		return emptyMultiResults, err
+	}
+
+	return multiResults, err
+}
+
+// marshalPartialWalkResult encodes the results of a walk with the partial option (always as JSON)
+func marshalPartialWalkResult(multiResults []multiResult, err error, options walkOptions) (string, error) {
+	result := partialWalkResult{
+		Results: multiResults,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		result.LastOID = getLastOID(multiResults, options)
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return "{}", err
+	}
+
+	return string(resultBytes), nil
+}
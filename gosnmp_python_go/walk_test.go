@@ -0,0 +1,142 @@
+package gosnmp_python_go
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const ifDescrOID = ".1.3.6.1.2.1.2.2.1.2"
+
+func walkAllWays(t *testing.T, sessionID uint64, oid string, options walkOptions, expected int) map[string]error {
+	s, ok := getSession(sessionID)
+	if !ok {
+		t.Fatalf("session %v does not exist", sessionID)
+	}
+
+	errs := make(map[string]error)
+	counts := make(map[string]int)
+
+	var multiResults []multiResult
+	var err error
+
+	multiResults, err = s.walk(oid, options)
+	errs["walk"], counts["walk"] = err, len(multiResults)
+
+	multiResults, err = s.walkBulk(oid, options)
+	errs["walkBulk"], counts["walkBulk"] = err, len(multiResults)
+
+	multiResults, err = s.walkMany([]string{oid}, options)
+	errs["walkMany"], counts["walkMany"] = err, len(multiResults)
+
+	for way, count := range counts {
+		if errs[way] == nil && count != expected {
+			t.Errorf("%v: expected %v results, got %v", way, expected, count)
+		}
+	}
+
+	return errs
+}
+
+func TestWalkStopsAtRepeatedOID(t *testing.T) {
+	a := newTestAgent(t, testIfTable(5))
+	defer a.close()
+
+	// the agent keeps answering with ifDescr.3
+	a.setJump(ifDescrOID+".3", ifDescrOID+".3")
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	for way, err := range walkAllWays(t, sessionID, ifDescrOID, walkOptions{}, 3) {
+		if err != nil {
+			t.Errorf("%v: expected the repeat to end the walk, got %v", way, err)
+		}
+	}
+}
+
+func TestWalkFailsOnDecreasingOID(t *testing.T) {
+	a := newTestAgent(t, testIfTable(5))
+	defer a.close()
+
+	// the agent goes back to ifDescr.2
+	a.setJump(ifDescrOID+".3", ifDescrOID+".2")
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	expected := fmt.Sprintf("OID not increasing: %v.2 followed %v.3", ifDescrOID, ifDescrOID)
+
+	for way, err := range walkAllWays(t, sessionID, ifDescrOID, walkOptions{}, 3) {
+		if err == nil || err.Error() != expected {
+			t.Errorf("%v: expected %v, got %v", way, expected, err)
+		}
+	}
+}
+
+func TestWalkOptions(t *testing.T) {
+	a := newTestAgent(t, testIfTable(5))
+	defer a.close()
+
+	const ifEntryOID = ".1.3.6.1.2.1.2.2.1"
+
+	tests := []struct {
+		name     string
+		options  walkOptions
+		expected int
+	}{
+		{"exclude", walkOptions{Exclude: []string{ifDescrOID}}, 10},
+		{"exclude several", walkOptions{Exclude: []string{ifEntryOID + ".1", ifDescrOID}}, 5},
+		{"stop", walkOptions{StopOID: ifEntryOID + ".10"}, 10},
+		{"stop part way", walkOptions{StopOID: ifDescrOID + ".3"}, 7},
+		{"max varbinds", walkOptions{MaxVarbinds: 7}, 7},
+		{"exclude and max varbinds", walkOptions{Exclude: []string{ifDescrOID}, MaxVarbinds: 7}, 7},
+	}
+
+	// all in one response, and a few varbinds at a time (so the walk carries on from an excluded subtree)
+	for _, sessionOptions := range []string{"", `, "max_repetitions": 2`} {
+		sessionID := newTestSession(t, a, sessionOptions)
+
+		for _, test := range tests {
+			for way, err := range walkAllWays(t, sessionID, ifEntryOID, test.options, test.expected) {
+				if err != nil {
+					t.Errorf("%v%v: %v: failed to walk: %v", test.name, sessionOptions, way, err)
+				}
+			}
+		}
+
+		_ = RPCClose(sessionID)
+	}
+}
+
+func TestWalkBulkRelaxesTooBigMaxRepetitions(t *testing.T) {
+	a := newTestAgent(t, testIfTable(testIfTableRows))
+	defer a.close()
+
+	sessionID := newTestSession(t, a, "")
+	defer func() {
+		_ = RPCClose(sessionID)
+	}()
+
+	s, _ := getSession(sessionID)
+	w := s.(*session).snmp.(*wrappedSNMP)
+
+	// held just below a tooBig since well before the last update
+	w.tooBigMaxRepetitions = 11
+	w.optimalMaxRepetitions = 10
+	w.lastMaxRepetitionsUpdate = time.Now().Add(-updateInterval).Add(-time.Second)
+
+	_, err := s.walkBulk(ifDescrOID, walkOptions{})
+	if err != nil {
+		t.Fatalf("failed to walk: %v", err)
+	}
+
+	if w.tooBigMaxRepetitions != 0 || w.optimalMaxRepetitions <= 10 {
+		t.Errorf("expected the tooBig limit to be relaxed, got tooBigMaxRepetitions=%v, optimalMaxRepetitions=%v",
+			w.tooBigMaxRepetitions, w.optimalMaxRepetitions)
+	}
+}
@@ -15,12 +15,16 @@ type wrappedSNMPInterface interface {
 	getSNMP() *gosnmp.GoSNMP
 	getConn() net.PacketConn
 	connect() error
+	applyCallOverrides(timeout time.Duration, retries int) func()
 	get(oids []string) (result *gosnmp.SnmpPacket, err error)
 	getNext(oids []string) (result *gosnmp.SnmpPacket, err error)
 	getBulk(oids []string, nonRepeaters uint8, maxRepetitions uint8) (result *gosnmp.SnmpPacket, err error)
-	walk(oids []string) (result *gosnmp.SnmpPacket, err error)
-	walkBulk(oids []string) (result *gosnmp.SnmpPacket, err error)
+	walk(oids []string, options walkOptions) (result *gosnmp.SnmpPacket, err error)
+	walkBulk(oids []string, options walkOptions) (result *gosnmp.SnmpPacket, err error)
+	walkMany(oids []string, options walkOptions) (results []*gosnmp.SnmpPacket, err error)
 	set(pdus []gosnmp.SnmpPDU) (result *gosnmp.SnmpPacket, err error)
+	pipeline(requests []pipelineRequest) []pipelineResponse
+	canGetBulk() bool
 	close() error
 }
 
@@ -30,53 +34,248 @@ type wrappedSNMP struct {
 	optimalMaxRepetitions              uint8
 	lastMaxRepetitionsUpdate           time.Time
 	callsSinceLastMaxRepetitionsUpdate int64
+	transport                          string
+	retryPolicy                        retryPolicy
+	pipelineDepth                      int
+	requestID                          uint32
+	localAddress                       string
+	localPort                          int
+	localInterface                     string
+	maxMessageSize                     int            // see getMaxMessageSize
+	conn                               *measuringConn // wraps snmp.Conn
+	varbindSize                        float64        // moving average of the bytes per varbind in GetBulk responses
+	tooBigMaxRepetitions               uint8          // the least maxRepetitions the agent has said is tooBig; 0 if it hasn't (or we've since relaxed it)
+	emulateGetBulk                     bool           // SNMPv1 only (see emulatedGetBulk)
+	maxRepetitionsGiven                bool           // the session was given a max_repetitions (so isn't seeded by a targetProfile)
 }
 
 func (w *wrappedSNMP) getSNMP() *gosnmp.GoSNMP {
 	return w.snmp
 }
 
+func (w *wrappedSNMP) getNetwork() string {
+	if w.transport == "" {
+		return "udp"
+	}
+
+	return w.transport
+}
+
+func (w *wrappedSNMP) getProfileKey() profileKey {
+	return getProfileKey(w.snmp.Target, w.snmp.Port, w.snmp.Version)
+}
+
 func (w *wrappedSNMP) getConn() net.PacketConn {
 	return w.snmp.Conn
 }
 
 func (w *wrappedSNMP) connect() error {
 	// starting reps value to work down from
-	w.defaultMaxRepetitions = defaultMaxRepetitions
+	w.defaultMaxRepetitions = w.snmp.MaxRepetitions
+	if w.defaultMaxRepetitions == 0 {
+		w.defaultMaxRepetitions = defaultMaxRepetitions
+	}
 
 	// our current optimal reps based on which value gets the most responses
 	w.optimalMaxRepetitions = w.defaultMaxRepetitions
 
+	// request IDs for pipelined requests (see pipeline)
+	jitterMutex.Lock()
+	w.requestID = uint32(jitterRandom.Int31())
+	jitterMutex.Unlock()
+
 	// force an update off the bat
 	w.lastMaxRepetitionsUpdate = time.Now().Add(-updateInterval).Add(-time.Second)
 	w.callsSinceLastMaxRepetitionsUpdate = updateCallThreshold + 1
 
-	return w.snmp.Connect()
+	// unless an earlier session to the same device has already learned what works (see targetProfile) and we weren't told where to
+	// start; never above where we'd otherwise start, as what worked for one session may be more than another wants
+	profile, ok := getTargetProfile(w.getProfileKey())
+	if ok && profile.MaxRepetitions > 0 && !w.maxRepetitionsGiven {
+		w.optimalMaxRepetitions = profile.MaxRepetitions
+		if w.optimalMaxRepetitions > w.defaultMaxRepetitions {
+			w.optimalMaxRepetitions = w.defaultMaxRepetitions
+		}
+
+		w.lastMaxRepetitionsUpdate = time.Now()
+		w.callsSinceLastMaxRepetitionsUpdate = 0
+	}
+
+	if ok && profile.ResponseVarbinds >= 1 {
+		w.varbindSize = (profile.ResponseBytes - float64(estimateHeaderSize(w.snmp.Version, w.snmp.Community))) / profile.ResponseVarbinds
+	}
+
+	var err error
+
+	switch w.transport {
+	case "udp4":
+		err = w.snmp.ConnectIPv4()
+	case "udp6":
+		err = w.snmp.ConnectIPv6()
+	default:
+		err = w.snmp.Connect()
+	}
+	if err != nil {
+		return err
+	}
+
+	// gosnmp always listens on ":0", so swap its socket out for one bound the way we want (if we're not happy to send from whatever
+	// the kernel picks)
+	if w.localAddress != "" || w.localPort != 0 || w.localInterface != "" {
+		conn, err := listenPacket(w.getNetwork(), w.localAddress, w.localPort, w.localInterface)
+		if err != nil {
+			_ = w.snmp.Conn.Close()
+			return fmt.Errorf("Error establishing connection to host: %v", err)
+		}
+
+		_ = w.snmp.Conn.Close()
+		w.snmp.Conn = conn
+	}
+
+	// so we know how big the responses are (see getFittingMaxRepetitions)
+	w.conn = &measuringConn{
+		PacketConn: w.snmp.Conn,
+		isV3:       w.snmp.Version == gosnmp.Version3,
+	}
+	w.snmp.Conn = w.conn
+
+	return nil
+}
+
+func (w *wrappedSNMP) applyCallOverrides(timeout time.Duration, retries int) func() {
+	originalTimeout := w.snmp.Timeout
+	originalRetries := w.snmp.Retries
+
+	if timeout > 0 {
+		w.snmp.Timeout = timeout
+	}
+
+	if retries >= 0 {
+		w.snmp.Retries = retries
+	}
+
+	return func() {
+		w.snmp.Timeout = originalTimeout
+		w.snmp.Retries = originalRetries
+	}
+}
+
+// withRetries calls request, retrying as per the retryPolicy (if it calls for waiting between retries; gosnmp does it otherwise)
+func (w *wrappedSNMP) withRetries(request func() (*gosnmp.SnmpPacket, error)) (result *gosnmp.SnmpPacket, err error) {
+	request = w.recoveringUnknownValues(request)
+
+	if w.retryPolicy.isImmediate() || w.snmp.Retries <= 0 {
+		return request()
+	}
+
+	originalTimeout := w.snmp.Timeout
+	originalRetries := w.snmp.Retries
+
+	// each attempt gets the same share of the timeout that gosnmp would have given it
+	w.snmp.Timeout = originalTimeout / time.Duration(originalRetries+1)
+	w.snmp.Retries = 0
+
+	defer func() {
+		w.snmp.Timeout = originalTimeout
+		w.snmp.Retries = originalRetries
+	}()
+
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			delay := w.retryPolicy.getDelay(retry)
+
+			if w.snmp.Logger != nil {
+				w.snmp.Logger.Printf("retry %v of %v in %v; last error was: %v", retry, originalRetries, delay, err)
+			}
+
+			time.Sleep(delay)
+		}
+
+		result, err = request()
+		if err == nil || retry >= originalRetries {
+			return result, err
+		}
+	}
+}
+
+// recoveringUnknownValues wraps request so that whatever gosnmp couldn't decode in its response is recovered from the datagram it came
+// in (see recoverUnknownValues)
+func (w *wrappedSNMP) recoveringUnknownValues(request func() (*gosnmp.SnmpPacket, error)) func() (*gosnmp.SnmpPacket, error) {
+	return func() (*gosnmp.SnmpPacket, error) {
+		result, err := request()
+		if err == nil && w.conn != nil {
+			recoverUnknownValues(result, w.conn.getLastRead())
+		}
+
+		return result, err
+	}
 }
 
 func (w *wrappedSNMP) get(oids []string) (result *gosnmp.SnmpPacket, err error) {
-	return w.snmp.Get(formatOIDs(oids))
+	oids, err = formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.withRetries(func() (*gosnmp.SnmpPacket, error) {
+		return w.snmp.Get(oids)
+	})
 }
 
 func (w *wrappedSNMP) getNext(oids []string) (result *gosnmp.SnmpPacket, err error) {
-	return w.snmp.GetNext(formatOIDs(oids))
+	oids, err = formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.withRetries(func() (*gosnmp.SnmpPacket, error) {
+		return w.snmp.GetNext(oids)
+	})
 }
 
 func (w *wrappedSNMP) getBulk(oids []string, nonRepeaters uint8, maxRepetitions uint8) (result *gosnmp.SnmpPacket, err error) {
+	oids, err = formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
+
 	if w.snmp.Version == gosnmp.Version1 {
-		return nil, fmt.Errorf("cannot call BULKWALK with SNMPv1")
+		if !w.emulateGetBulk {
+			return nil, fmt.Errorf("cannot call BULKWALK with SNMPv1")
+		}
+
+		return w.emulatedGetBulk(oids, nonRepeaters, maxRepetitions)
 	}
 
-	result, err = w.snmp.GetBulk(formatOIDs(oids), nonRepeaters, maxRepetitions)
+	start := time.Now()
+
+	if w.conn != nil {
+		w.conn.takeTooBig()
+	}
+
+	result, err = w.withRetries(func() (*gosnmp.SnmpPacket, error) {
+		result, err := w.snmp.GetBulk(oids, nonRepeaters, maxRepetitions)
+
+		// gosnmp will have dropped a tooBig response with no varbinds and timed out (see measuringConn)
+		if err != nil && w.conn != nil && w.conn.takeTooBig() {
+			return &gosnmp.SnmpPacket{Version: w.snmp.Version, PDUType: gosnmp.GetResponse, Error: gosnmp.TooBig}, nil
+		}
+
+		return result, err
+	})
+
+	if err == nil && result != nil && result.Error == gosnmp.NoError {
+		recordGetBulkResponse(w.getProfileKey(), result, w.recordResponseSize(result), time.Since(start))
+	}
 
 	return result, err
 }
 
-func (w *wrappedSNMP) isThereMoreToWalk(oid string, originalOID string) (bool, gosnmp.SnmpPDU) {
-	// if get next returns nothing, we're good
-	nextResult, _ := w.getNext([]string{formatOID(oid)})
+func (w *wrappedSNMP) isThereMoreToWalk(oid string, originalOID string) (bool, gosnmp.SnmpPDU, error) {
+	// if get next returns nothing, we're good (but pass on why, in case the caller cares)
+	nextResult, err := w.getNext([]string{oid})
 	if nextResult == nil || len(nextResult.Variables) == 0 {
-		return false, gosnmp.SnmpPDU{}
+		return false, gosnmp.SnmpPDU{}, err
 	}
 
 	// can only be one variable from getNext
@@ -84,27 +283,25 @@ func (w *wrappedSNMP) isThereMoreToWalk(oid string, originalOID string) (bool, g
 
 	// we've reached the end, we're good
 	if isThisAnEndVariable(nextVariable) {
-		return false, gosnmp.SnmpPDU{}
-	}
-
-	// if get next returned the same oid, we're as good as we can be without chopping off OIDs
-	if nextVariable.Name == oid {
-		return false, gosnmp.SnmpPDU{}
+		return false, gosnmp.SnmpPDU{}, nil
 	}
 
 	// if get next returned something outside the parent tree, we're good
 	if !hasOIDPrefix(nextVariable.Name, originalOID) {
-		return false, gosnmp.SnmpPDU{}
+		return false, gosnmp.SnmpPDU{}, nil
 	}
 
-	return true, nextResult.Variables[0]
+	return true, nextResult.Variables[0], nil
 }
 
-func (w *wrappedSNMP) specialWalk(oid string, originalOID string) (result *gosnmp.SnmpPacket, err error) {
+func (w *wrappedSNMP) specialWalk(oid string, originalOID string, options walkOptions) (result *gosnmp.SnmpPacket, err error) {
 	/*
 	   There's a bit of special stuff in this function that deviates slightly from a normal walk in that
 	   it'll try to find the next sibling and keeping walking from there (as long as it doesn't leave the
 	   parent tree).
+
+	   The options may also stop the walk early (at an OID or after a number of varbinds) or have it skip
+	   over excluded subtrees (by moving the cursor to the end of the subtree rather than walking it).
 	*/
 
 	result = &gosnmp.SnmpPacket{
@@ -113,38 +310,68 @@ func (w *wrappedSNMP) specialWalk(oid string, originalOID string) (result *gosnm
 
 	ok := false
 	var thisPDU gosnmp.SnmpPDU
-	var lastPDU gosnmp.SnmpPDU
+	orderChecker := newOIDOrderChecker(options)
 
 	for {
 		// this is GetNext underneath, with some logic
-		ok, thisPDU = w.isThereMoreToWalk(formatOID(oid), originalOID)
+		ok, thisPDU, err = w.isThereMoreToWalk(oid, originalOID)
 
 		// no more walking required
 		if !ok {
 			break
 		}
 
-		// got same result- so no more walking required
-		if thisPDU.Name == lastPDU.Name {
+		// got the same OID back- the agent isn't going anywhere, which is as good as the end of the walk
+		if thisPDU.Name == oid {
+			break
+		}
+
+		// got one from before- the agent has gone backwards (or round in circles)
+		err = orderChecker.check(oid, thisPDU.Name)
+		if err != nil {
+			break
+		}
+
+		// reached the stop OID
+		if options.isPastStop(thisPDU.Name) {
 			break
 		}
 
+		// in an excluded subtree- jump to the end of it
+		skipOID, skip := options.getSkipOID(thisPDU.Name)
+		if skip {
+			oid = skipOID
+			continue
+		}
+
 		// record what we got
 		result.Variables = append(result.Variables, thisPDU)
 
 		// and move our OID cursor
 		oid = thisPDU.Name
-		lastPDU = thisPDU
+
+		// got as much as we're allowed
+		if options.isFull(len(result.Variables)) {
+			break
+		}
 	}
 
 	deduplicateResult(result)
 
+	// a device that stops responding is taken as the end of the walk, unless the caller wants to know (so it can resume)
+	if _, nonIncreasing := err.(nonIncreasingOIDError); !nonIncreasing && !options.Partial {
+		err = nil
+	}
+
 	return result, err
 }
 
 // TODO: slice not actually needed, but keeping the interface consistent
-func (w *wrappedSNMP) walk(oids []string) (result *gosnmp.SnmpPacket, err error) {
-	oids = formatOIDs(oids)
+func (w *wrappedSNMP) walk(oids []string, options walkOptions) (result *gosnmp.SnmpPacket, err error) {
+	oids, err = formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(oids) != 1 {
 		return nil, fmt.Errorf("oids length must be exactly 1")
@@ -159,11 +386,19 @@ func (w *wrappedSNMP) walk(oids []string) (result *gosnmp.SnmpPacket, err error)
 	oid := oids[0]
 	originalOID := oid
 
-	return w.specialWalk(oid, originalOID)
+	if options.ResumeOID != "" {
+		if !hasOIDPrefix(options.ResumeOID, originalOID) {
+			return nil, fmt.Errorf("resume_oid %v is not within %v", options.ResumeOID, originalOID)
+		}
+
+		oid = options.ResumeOID
+	}
+
+	return w.specialWalk(oid, originalOID, options)
 }
 
 // TODO: slice not actually needed, but keeping the interface consistent
-func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err error) {
+func (w *wrappedSNMP) walkBulk(oids []string, options walkOptions) (result *gosnmp.SnmpPacket, err error) {
 	/*
 	   There's some special stuff in here to allow us to get the benefit from bulk commands without
 	   being a slave to the way the target device walks itself. In summary:
@@ -173,6 +408,7 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 	   - finish off with a specialWalk if all we can't decrement optimalMaxRepetitions any further
 	   - record progress throughout and adjust optimalMaxRepetitions value (for future use)
 	   - reassess that value every 30s or 30 successful responses
+	   - honour the options as per specialWalk (skipping excluded subtrees by moving the cursor past them)
 	*/
 
 	// help us fail fast if there's nothing at this OID or if this device is offline
@@ -181,9 +417,12 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 		return result, err
 	}
 
-	oids = formatOIDs(oids)
+	oids, err = formatOIDs(oids)
+	if err != nil {
+		return nil, err
+	}
 
-	if w.snmp.Version == gosnmp.Version1 {
+	if !w.canGetBulk() {
 		return nil, fmt.Errorf("cannot call BULKWALK with SNMPv1")
 	}
 
@@ -191,6 +430,10 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 		return nil, fmt.Errorf("oids length must be exactly 1")
 	}
 
+	if options.ResumeOID != "" && !hasOIDPrefix(options.ResumeOID, oids[0]) {
+		return nil, fmt.Errorf("resume_oid %v is not within %v", options.ResumeOID, oids[0])
+	}
+
 	// TODO: can't use BulkWalkAll, suffers when the OID tree is a funny shape- use a variant on GetBulk
 	// pdus, err := w.snmp.BulkWalkAll(oids[0])
 	// if err != nil {
@@ -208,18 +451,35 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 
 	originalOID := oids[0]
 	oid := originalOID
+	if options.ResumeOID != "" {
+		oid = options.ResumeOID
+	}
 	var thisResult *gosnmp.SnmpPacket
 	exhaustedRetries := false
+	failures := 0
+	orderChecker := newOIDOrderChecker(options)
 
 	for {
-		// if we're due to reassess defaultMaxRepetitions
+		// as many as should fit in a response (see getFittingMaxRepetitions)
+		fittingMaxRepetitions := w.getFittingMaxRepetitions()
+
+		// if we're due to reassess optimalMaxRepetitions
 		if time.Now().After(w.lastMaxRepetitionsUpdate.Add(updateInterval)) || w.callsSinceLastMaxRepetitionsUpdate > updateCallThreshold {
-			if w.optimalMaxRepetitions < w.defaultMaxRepetitions {
-				// e.g. 1, 2, 3, 4, 5, 10, 15, ... 100
+			// we've been held just below what the agent said was tooBig since the last update; the responses may have got smaller
+			// (or the agent been reconfigured) since, so let it be tried again
+			if w.tooBigMaxRepetitions > 0 && w.optimalMaxRepetitions >= w.tooBigMaxRepetitions-1 {
+				w.tooBigMaxRepetitions = 0
+				fittingMaxRepetitions = w.getFittingMaxRepetitions()
+			}
+
+			if w.optimalMaxRepetitions < fittingMaxRepetitions {
+				// e.g. 1, 2, 3, 4, 5, 10, 15, ... 100 (and no more than will fit)
 				if w.optimalMaxRepetitions < 5 {
 					w.optimalMaxRepetitions += 1
-				} else {
+				} else if w.optimalMaxRepetitions < fittingMaxRepetitions-5 {
 					w.optimalMaxRepetitions += 5
+				} else {
+					w.optimalMaxRepetitions = fittingMaxRepetitions
 				}
 
 				w.lastMaxRepetitionsUpdate = time.Now()
@@ -227,6 +487,11 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 			}
 		}
 
+		// the responses have got bigger (or we've learned how big they are)- ask for no more than will fit
+		if w.optimalMaxRepetitions > fittingMaxRepetitions {
+			w.optimalMaxRepetitions = fittingMaxRepetitions
+		}
+
 		if w.snmp.Logger != nil {
 			w.snmp.Logger.Printf(
 				"lastMaxRepetitionsUpdate=%v, callsSinceLastMaxRepetitionsUpdate=%v, optimalMaxRepetitions=%v",
@@ -261,6 +526,31 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 			w.lastMaxRepetitionsUpdate = time.Now()
 			w.callsSinceLastMaxRepetitionsUpdate = 0
 
+			// give a struggling device some breathing room (as per the retryPolicy) before we go again
+			failures++
+			time.Sleep(w.retryPolicy.getDelay(failures))
+
+			continue
+		}
+
+		failures = 0
+
+		// the agent has told us the response wouldn't fit- ask for fewer straight away (it's not struggling, so no need to wait)
+		if thisResult != nil && thisResult.Error == gosnmp.TooBig {
+			if w.optimalMaxRepetitions <= 1 {
+				exhaustedRetries = true
+				break
+			}
+
+			if w.tooBigMaxRepetitions == 0 || w.optimalMaxRepetitions < w.tooBigMaxRepetitions {
+				w.tooBigMaxRepetitions = w.optimalMaxRepetitions
+			}
+
+			w.optimalMaxRepetitions /= 2
+
+			w.lastMaxRepetitionsUpdate = time.Now()
+			w.callsSinceLastMaxRepetitionsUpdate = 0
+
 			continue
 		}
 
@@ -273,26 +563,61 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 
 		w.callsSinceLastMaxRepetitionsUpdate++
 
-		// filter anything out of our tree (GetBulk will just keep returning what's next)
+		// filter anything out of our tree (GetBulk will just keep returning what's next) or excluded by the options
 		filteredVariables := make([]gosnmp.SnmpPDU, 0)
+		nextOID := ""
+		previousOID := oid
+		done := false
 		for _, variable := range thisResult.Variables {
-			if !hasOIDPrefix(variable.Name, originalOID) {
+			if !hasOIDPrefix(variable.Name, originalOID) || options.isPastStop(variable.Name) {
+				done = true
+				break
+			}
+
+			if isThisAnEndVariable(variable) {
+				break
+			}
+
+			// the same OID back is as good as the end of the walk
+			if variable.Name == previousOID {
+				done = true
+				break
+			}
+
+			// the agent has gone backwards (or round in circles)
+			err = orderChecker.check(previousOID, variable.Name)
+			if err != nil {
+				done = true
+				break
+			}
+
+			previousOID = variable.Name
+			nextOID = variable.Name
+
+			skipOID, skip := options.getSkipOID(variable.Name)
+			if skip {
+				nextOID = skipOID
 				continue
 			}
 
 			filteredVariables = append(filteredVariables, variable)
-		}
 
-		// if we got nothing in our tree, then we're done
-		if len(filteredVariables) == 0 {
-			break
+			if options.isFull(len(result.Variables) + len(filteredVariables)) {
+				done = true
+				break
+			}
 		}
 
 		// record what we got
 		result.Variables = append(result.Variables, filteredVariables...)
 
+		// if we got nothing in our tree (or have all we're allowed), then we're done
+		if done || nextOID == "" || nextOID == oid {
+			break
+		}
+
 		// and move our OID cursor
-		oid = result.Variables[len(result.Variables)-1].Name
+		oid = nextOID
 
 		// we've reached the end, we're good
 		if isThisAnEndVariable(thisResult.Variables[len(thisResult.Variables)-1]) {
@@ -301,8 +626,14 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 	}
 
 	if exhaustedRetries {
-		thisResult, err = w.specialWalk(oid, originalOID)
-		if err == nil && thisResult != nil && len(thisResult.Variables) > 0 {
+		remainingOptions := options
+		if options.MaxVarbinds > 0 {
+			remainingOptions.MaxVarbinds = options.MaxVarbinds - len(result.Variables)
+		}
+
+		// NOTE: with the partial option, this also returns what it got before it failed
+		thisResult, err = w.specialWalk(oid, originalOID, remainingOptions)
+		if thisResult != nil && len(thisResult.Variables) > 0 {
 			result.Variables = append(result.Variables, thisResult.Variables...)
 		}
 	}
@@ -311,12 +642,17 @@ func (w *wrappedSNMP) walkBulk(oids []string) (result *gosnmp.SnmpPacket, err er
 
 	w.snmp.Retries = originalRetries
 
+	// for future sessions to the same device
+	recordMaxRepetitions(w.getProfileKey(), w.optimalMaxRepetitions)
+
 	return result, err
 
 }
 
 func (w *wrappedSNMP) set(pdus []gosnmp.SnmpPDU) (result *gosnmp.SnmpPacket, err error) {
-	return w.snmp.Set(pdus)
+	return w.withRetries(func() (*gosnmp.SnmpPacket, error) {
+		return w.snmp.Set(pdus)
+	})
 }
 
 func (w *wrappedSNMP) close() error {